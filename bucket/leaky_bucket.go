@@ -1,6 +1,7 @@
 package bucket
 
 import (
+	"sync"
 	"time"
 )
 
@@ -12,9 +13,10 @@ type Configuration struct {
 
 type LeakyBucket struct {
 	Configuration
-	bucket      chan (bool)
-	stopFilling chan (bool)
-	ticker      *time.Ticker
+	bucket   chan (bool)
+	mutex    sync.Mutex
+	drippers int
+	stop     chan (bool)
 }
 
 func NewLeakyBucket(size, refill, duration int) *LeakyBucket {
@@ -24,28 +26,43 @@ func NewLeakyBucket(size, refill, duration int) *LeakyBucket {
 func NewLeakyBucketWithConfiguration(configuration Configuration) *LeakyBucket {
 	b := &LeakyBucket{Configuration: configuration}
 	b.bucket = make(chan bool, b.Size)
-	b.stopFilling = make(chan bool)
-	b.ticker = time.NewTicker(b.Duration)
 	return b
 }
 
+// StartDripping refills the bucket until every caller of StartDripping has called StopDripping.
+// The bucket drips at its configured rate however many callers share it.
 func (b *LeakyBucket) StartDripping() {
-	go func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.drippers++
+	if b.drippers > 1 {
+		return
+	}
+	b.stop = make(chan bool)
+	go func(stop chan bool) {
+		ticker := time.NewTicker(b.Duration)
+		defer ticker.Stop()
 		for {
 			select {
-			case <-b.stopFilling:
+			case <-stop:
 				return
-			case <-b.ticker.C:
+			case <-ticker.C:
 				b.fill(b.Refill)
 			}
 		}
-	}()
+	}(b.stop)
 }
 
 func (b *LeakyBucket) StopDripping() {
-	go func() {
-		b.stopFilling <- true
-	}()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.drippers == 0 {
+		return
+	}
+	b.drippers--
+	if b.drippers == 0 {
+		close(b.stop)
+	}
 }
 
 func (b *LeakyBucket) Available() int {
@@ -68,6 +85,29 @@ func (b *LeakyBucket) AddDrops() {
 	b.fill(b.Refill)
 }
 
+// Resync sets the drops available to match the usage reported by the server, used
+// calls out of limit, scaled to the size of this bucket. When the server reports it is
+// nearly full requests slow down, and they speed back up as the server drains.
+func (b *LeakyBucket) Resync(used, limit int) {
+	if limit <= 0 {
+		return
+	}
+	if used > limit {
+		used = limit
+	} else if used < 0 {
+		used = 0
+	}
+	target := (limit - used) * b.Size / limit
+	for b.Available() > target {
+		select {
+		case <-b.bucket:
+		default:
+			return
+		}
+	}
+	b.fill(target - b.Available())
+}
+
 func (b *LeakyBucket) GetDrop() {
 	<-b.bucket
 }
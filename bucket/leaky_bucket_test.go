@@ -67,6 +67,43 @@ func TestFillingAnAlreadyFullBucket(t *testing.T) {
 	assert.Equal(t, 2, bucket.Available())
 }
 
+func TestResyncingABucketWithTheServerUsage(t *testing.T) {
+	config := Configuration{Size: 20, Refill: 1, Duration: time.Duration(10) * time.Millisecond}
+	bucket := NewLeakyBucketWithConfiguration(config)
+	bucket.TopUp()
+
+	bucket.Resync(36, 40)
+	assert.Equal(t, 2, bucket.Available(), "a nearly full server should leave few drops")
+
+	bucket.Resync(50, 40)
+	assert.Equal(t, 0, bucket.Available())
+
+	bucket.Resync(10, 40)
+	assert.Equal(t, 15, bucket.Available(), "drops should come back as the server drains")
+
+	bucket.Resync(10, 0)
+	assert.Equal(t, 15, bucket.Available(), "invalid limits are ignored")
+}
+
+func TestSharingTheDrippingOfABucket(t *testing.T) {
+	config := Configuration{Size: 20, Refill: 1, Duration: time.Duration(10) * time.Millisecond}
+	bucket := NewLeakyBucketWithConfiguration(config)
+	bucket.StartDripping()
+	bucket.StartDripping()
+	time.Sleep(55 * time.Millisecond)
+	bucket.StopDripping()
+	available := bucket.Available()
+	assert.True(t, available >= 3 && available <= 7, "Dripping is not doubled by a second caller")
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, bucket.Available() > available, "Dripping goes on until every caller stopped")
+	bucket.StopDripping()
+	time.Sleep(15 * time.Millisecond)
+	stopped := bucket.Available()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, bucket.Available())
+}
+
 func BenchmarkBucket(b *testing.B) {
 	config := Configuration{Size: 2, Refill: 1, Duration: time.Duration(1) * time.Millisecond}
 	bucket := NewLeakyBucketWithConfiguration(config)
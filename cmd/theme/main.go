@@ -22,10 +22,16 @@ const updateAvailableMessage string = `| An update for Theme Kit is available |
 |                                      |
 | theme update                         |`
 
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+)
+
 var globalEventLog chan themekit.ThemeEvent
+var outputFormat = textOutput
 
 var commandDescriptionPrefix = []string{
-	"Usage: theme <operation> [<additional arguments> ...]",
+	"Usage: theme [--output text|json] <operation> [<additional arguments> ...]",
 	"  Valid operations are:",
 }
 
@@ -34,9 +40,19 @@ var permittedCommands = map[string]string{
 	"download [<file> ...]":       "Download file(s) from theme",
 	"remove <file> [<file2> ...]": "Remove file(s) from theme",
 	"replace [<file> ...]":        "Overwrite theme file(s)",
+	"merge <file> [<file2> ...]":  "Merge remote changes into local file(s)",
+	"diff [<file> ...]":           "Show differences between local and remote file(s)",
 	"watch":                       "Watch directory for changes and update remote theme",
+	"themes [<action>]":           "List, create, publish, rename, duplicate or delete themes",
+	"publish":                     "Make the environment's theme the live theme",
+	"rollback":                    "Restore the theme that was live before the last publish",
 	"configure":                   "Create a configuration file",
-	"bootstrap":                   "Bootstrap a new theme using Shopify Timber",
+	"bootstrap":                   "Bootstrap a new theme using Shopify Timber or another starter theme",
+	"new -from <dir|zip>":         "Create a new theme from a local directory or zip",
+	"package":                     "Build a zip of the theme that can be uploaded to Shopify",
+	"unpack <zip>":                "Extract a theme zip into the directory",
+	"validate [<file> ...]":       "Check the theme for problems before uploading it",
+	"lint [<file> ...]":           "Check the Liquid syntax of the theme",
 	"version":                     "Display themekit version",
 	"update":                      "Update application",
 }
@@ -75,11 +91,36 @@ var commandDefinitions = map[string]CommandDefinition{
 		Command:         commands.ReplaceCommand,
 		PermitsZeroArgs: true,
 	},
+	"merge": CommandDefinition{
+		ArgsParser:      fileManipulationArgsParser,
+		Command:         commands.MergeCommand,
+		PermitsZeroArgs: false,
+	},
+	"diff": CommandDefinition{
+		ArgsParser:      fileManipulationArgsParser,
+		Command:         commands.DiffCommand,
+		PermitsZeroArgs: true,
+	},
 	"watch": CommandDefinition{
 		ArgsParser:      watchArgsParser,
 		Command:         commands.WatchCommand,
 		PermitsZeroArgs: true,
 	},
+	"themes": CommandDefinition{
+		ArgsParser:      themesArgsParser,
+		Command:         commands.ThemesCommand,
+		PermitsZeroArgs: true,
+	},
+	"publish": CommandDefinition{
+		ArgsParser:      publishArgsParser,
+		Command:         commands.PublishCommand,
+		PermitsZeroArgs: true,
+	},
+	"rollback": CommandDefinition{
+		ArgsParser:      publishArgsParser,
+		Command:         commands.RollbackCommand,
+		PermitsZeroArgs: true,
+	},
 	"configure": CommandDefinition{
 		ArgsParser:      configurationArgsParser,
 		Command:         commands.ConfigureCommand,
@@ -90,6 +131,31 @@ var commandDefinitions = map[string]CommandDefinition{
 		Command:         commands.BootstrapCommand,
 		PermitsZeroArgs: false,
 	},
+	"new": CommandDefinition{
+		ArgsParser:      newThemeParser,
+		Command:         commands.NewCommand,
+		PermitsZeroArgs: true,
+	},
+	"package": CommandDefinition{
+		ArgsParser:      localArgsParser,
+		Command:         commands.PackageCommand,
+		PermitsZeroArgs: true,
+	},
+	"unpack": CommandDefinition{
+		ArgsParser:      localArgsParser,
+		Command:         commands.UnpackCommand,
+		PermitsZeroArgs: false,
+	},
+	"validate": CommandDefinition{
+		ArgsParser:      localArgsParser,
+		Command:         commands.ValidateCommand,
+		PermitsZeroArgs: true,
+	},
+	"lint": CommandDefinition{
+		ArgsParser:      localArgsParser,
+		Command:         commands.LintCommand,
+		PermitsZeroArgs: true,
+	},
 	"version": CommandDefinition{
 		ArgsParser:      noOpParser,
 		Command:         commands.VersionCommand,
@@ -103,13 +169,14 @@ var commandDefinitions = map[string]CommandDefinition{
 }
 
 func main() {
+	arguments := setupOutputFormat(os.Args[1:])
 	setupGlobalEventLog()
 	setupErrorReporter()
 
-	command, rest := setupAndParseArgs(os.Args[1:])
+	command, rest := setupAndParseArgs(arguments)
 	verifyCommand(command, rest)
 
-	if command != "update" {
+	if command != "update" && outputFormat == textOutput {
 		go checkForUpdate()
 	}
 
@@ -118,6 +185,7 @@ func main() {
 	args := commandDefinition.ArgsParser(command, rest)
 	args.EventLog = globalEventLog
 
+	outcomes := commands.NewOutcomes()
 	done := commandDefinition.Command(args)
 	output := bufio.NewWriter(os.Stdout)
 	go func() {
@@ -125,8 +193,9 @@ func main() {
 		for {
 			select {
 			case event := <-globalEventLog:
-				if len(event.String()) > 0 {
-					output.WriteString(fmt.Sprintf("%s\n", event))
+				outcomes.Record(event)
+				if line := formatEvent(event); len(line) > 0 {
+					output.WriteString(line + "\n")
 					output.Flush()
 				}
 			case <-time.Tick(1000 * time.Millisecond):
@@ -140,6 +209,10 @@ func main() {
 	<-done
 	<-done
 	output.Flush()
+	if summary, found := outcomes.Summary(); found {
+		fmt.Println(formatEvent(summary))
+	}
+	os.Exit(outcomes.ExitCode())
 }
 
 func commandDescription() string {
@@ -159,7 +232,54 @@ func commandDescription() string {
 }
 
 func setupErrorReporter() {
-	themekit.SetErrorReporter(themekit.HaltExecutionReporter{})
+	if outputFormat == jsonOutput {
+		themekit.SetErrorReporter(themekit.JSONReporter{})
+	} else {
+		themekit.SetErrorReporter(themekit.HaltExecutionReporter{})
+	}
+}
+
+// setupOutputFormat removes the global --output flag from the arguments, wherever it appears,
+// and returns the arguments left
+func setupOutputFormat(args []string) []string {
+	rest := []string{}
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		switch {
+		case args[i] != name && name == "output" && i+1 < len(args):
+			outputFormat = args[i+1]
+			i++
+		case args[i] != name && strings.HasPrefix(name, "output="):
+			outputFormat = strings.TrimPrefix(name, "output=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	if outputFormat != textOutput && outputFormat != jsonOutput {
+		fmt.Println(themekit.RedText(fmt.Sprintf("'%s' is not a valid output format, use text or json", outputFormat)))
+		os.Exit(1)
+	}
+	themekit.SetColorOutput(outputFormat == textOutput)
+	if outputFormat == jsonOutput {
+		themekit.SetNoticeOutput(os.Stderr)
+	}
+	return rest
+}
+
+// formatEvent renders an event for the output format, events without a message are not shown
+func formatEvent(event themekit.ThemeEvent) string {
+	if len(event.String()) == 0 {
+		return ""
+	}
+	if outputFormat == textOutput {
+		return event.String()
+	}
+	data, err := themekit.EncodeEvent(event, time.Now())
+	if err != nil {
+		return ""
+	}
+	return string(data)
 }
 
 func setupGlobalEventLog() {
@@ -184,6 +304,11 @@ func fileManipulationArgsParser(cmd string, rawArgs []string) commands.Args {
 	set := makeFlagSet(cmd)
 	set.StringVar(&args.Environment, "env", themekit.DefaultEnvironment, "environment to run command")
 	set.StringVar(&args.Directory, "dir", currentDir, "directory that config.yml is located")
+	set.BoolVar(&args.DryRun, "dry-run", false, "print the changes that would be made to the theme without making them")
+	set.BoolVar(&args.Force, "force", false, "overwrite remote assets even if they changed since they were last synced")
+	if cmd == "upload" {
+		set.BoolVar(&args.Lint, "lint", false, "check the Liquid syntax of files and skip the ones with problems")
+	}
 	set.Parse(rawArgs)
 
 	args.ThemeClient = loadThemeClient(args.Directory, args.Environment)
@@ -201,8 +326,17 @@ func watchArgsParser(cmd string, rawArgs []string) commands.Args {
 	set.BoolVar(&allEnvironments, "allenvs", false, "start watchers for all environments")
 	set.StringVar(&args.Directory, "dir", currentDir, "directory that config.yml is located")
 	set.StringVar(&args.NotifyFile, "notify", "", "file to touch when workers have gone idle")
+	set.BoolVar(&args.Force, "force", false, "overwrite remote assets even if they changed since they were last synced")
+	set.BoolVar(&args.Lint, "lint", false, "check the Liquid syntax of files and skip the ones with problems")
+	set.DurationVar(&args.PollInterval, "poll", 0, "look for changes at this interval (e.g. 2s) instead of relying on filesystem notifications")
+	set.BoolVar(&args.Sync, "sync", false, "also download the changes made on Shopify, reporting conflicts when both sides changed")
+	set.DurationVar(&args.SyncInterval, "sync-interval", commands.DefaultSyncInterval, "how often to look for changes made on Shopify with -sync")
 	set.Parse(rawArgs)
 
+	if args.Sync && allEnvironments {
+		themekit.NotifyErrorImmediately(errors.New("-sync cannot be used with -allenvs: every environment would write into the same directory"))
+	}
+
 	if len(args.Environment) != 0 && allEnvironments {
 		args.Environment = ""
 	}
@@ -217,6 +351,47 @@ func watchArgsParser(cmd string, rawArgs []string) commands.Args {
 	return args
 }
 
+func themesArgsParser(cmd string, rawArgs []string) commands.Args {
+	args := commands.DefaultArgs()
+	currentDir, _ := os.Getwd()
+
+	// the action comes before its flags, as in 'theme themes publish -id 123'
+	if len(rawArgs) > 0 && !strings.HasPrefix(rawArgs[0], "-") {
+		args.Filenames = []string{rawArgs[0]}
+		rawArgs = rawArgs[1:]
+	}
+
+	set := makeFlagSet(cmd)
+	set.Usage = func() {
+		themekit.Notice(fmt.Sprintf("Usage of theme %s [%s]:", cmd, strings.Join(commands.ThemeActions(), "|")))
+		set.PrintDefaults()
+	}
+	set.StringVar(&args.Environment, "env", themekit.DefaultEnvironment, "environment to run command")
+	set.StringVar(&args.Directory, "dir", currentDir, "directory that config.yml is located")
+	set.StringVar(&args.ThemeName, "name", "", "name of the theme to create, or the new name of a renamed or duplicated theme")
+	set.Int64Var(&args.ThemeID, "id", 0, "id of the theme to publish, rename, duplicate or delete")
+	set.Parse(rawArgs)
+
+	args.ThemeClient = loadThemeClient(args.Directory, args.Environment)
+	return args
+}
+
+func publishArgsParser(cmd string, rawArgs []string) commands.Args {
+	args := commands.DefaultArgs()
+	currentDir, _ := os.Getwd()
+
+	set := makeFlagSet(cmd)
+	set.StringVar(&args.Environment, "env", themekit.DefaultEnvironment, "environment to run command")
+	set.StringVar(&args.Directory, "dir", currentDir, "directory that config.yml is located")
+	if cmd == "publish" {
+		set.BoolVar(&args.Replace, "replace", false, "replace the remote theme with the local files before publishing it")
+	}
+	set.Parse(rawArgs)
+
+	args.ThemeClient = loadThemeClient(args.Directory, args.Environment)
+	return args
+}
+
 func configurationArgsParser(cmd string, rawArgs []string) commands.Args {
 	args := commands.DefaultArgs()
 	currentDir, _ := os.Getwd()
@@ -242,14 +417,58 @@ func bootstrapParser(cmd string, rawArgs []string) commands.Args {
 	set.StringVar(&args.Directory, "dir", currentDir, "location of config.yml")
 	set.BoolVar(&args.SetThemeID, "setid", true, "update config.yml with ID of created Theme")
 	set.StringVar(&args.Environment, "env", themekit.DefaultEnvironment, "environment to execute command")
-	set.StringVar(&args.Version, "version", commands.LatestRelease, "version of Shopify Timber to use")
-	set.StringVar(&args.Prefix, "prefix", "", "prefix to the Timber theme being created")
+	set.StringVar(&args.Version, "version", commands.LatestRelease, "version of the starter theme to use")
+	set.StringVar(&args.Prefix, "prefix", "", "prefix to the name of the theme being created")
+	set.StringVar(&args.Source, "source", "", "release feed URL, zip URL or local zip of the starter theme, defaults to Shopify Timber")
 	set.Parse(rawArgs)
 
 	args.ThemeClient = loadThemeClient(args.Directory, args.Environment)
 	return args
 }
 
+func newThemeParser(cmd string, rawArgs []string) commands.Args {
+	args := commands.DefaultArgs()
+	currentDir, _ := os.Getwd()
+
+	set := makeFlagSet(cmd)
+	set.StringVar(&args.Directory, "dir", currentDir, "location of config.yml")
+	set.StringVar(&args.Source, "from", currentDir, "directory or zip containing the theme files")
+	set.StringVar(&args.ThemeName, "name", "", "name of the theme, defaults to the name of the directory or zip")
+	set.BoolVar(&args.SetThemeID, "setid", true, "update config.yml with ID of created Theme")
+	set.StringVar(&args.Environment, "env", themekit.DefaultEnvironment, "environment to execute command")
+	set.Parse(rawArgs)
+
+	args.ThemeClient = loadThemeClient(args.Directory, args.Environment)
+	return args
+}
+
+// localArgsParser parses the arguments of commands that only work with local files
+func localArgsParser(cmd string, rawArgs []string) commands.Args {
+	args := commands.DefaultArgs()
+	currentDir, _ := os.Getwd()
+
+	set := makeFlagSet(cmd)
+	set.StringVar(&args.Environment, "env", themekit.DefaultEnvironment, "environment whose ignores are applied")
+	set.StringVar(&args.Directory, "dir", currentDir, "directory of the theme")
+	if cmd == "package" {
+		set.StringVar(&args.Archive, "file", "", "zip to create, defaults to the name of the directory")
+	}
+	set.Parse(rawArgs)
+
+	args.ThemeClient = loadOptionalThemeClient(args.Directory, args.Environment)
+	args.Filenames = rawArgs[len(rawArgs)-set.NArg():]
+	return args
+}
+
+// loadOptionalThemeClient loads the client of an environment when there is a config.yml, for
+// commands that only need its ignores and otherwise use the default ones
+func loadOptionalThemeClient(directory, env string) themekit.ThemeClient {
+	if _, err := os.Stat(filepath.Join(directory, "config.yml")); os.IsNotExist(err) {
+		return themekit.NewThemeClient(themekit.Configuration{})
+	}
+	return loadThemeClient(directory, env)
+}
+
 func loadThemeClient(directory, env string) themekit.ThemeClient {
 	client, err := loadThemeClientWithRetry(directory, env, false)
 	handleError(err)
@@ -264,14 +483,14 @@ func loadThemeClientWithRetry(directory, env string, isRetry bool) (themekit.The
 	config, err := environments.GetConfiguration(env)
 	if err != nil && len(environments) > 0 {
 		invalidEnvMsg := fmt.Sprintf("'%s' is not a valid environment. The following environments are available within config.yml:", env)
-		fmt.Println(themekit.RedText(invalidEnvMsg))
+		themekit.Notice(themekit.RedText(invalidEnvMsg))
 		for e := range environments {
-			fmt.Println(themekit.RedText(fmt.Sprintf(" - %s", e)))
+			themekit.Notice(themekit.RedText(fmt.Sprintf(" - %s", e)))
 		}
 		os.Exit(1)
 	} else if err != nil && !isRetry {
 		upgradeMessage := fmt.Sprintf("Looks like your configuration file is out of date. Upgrading to default environment '%s'", themekit.DefaultEnvironment)
-		fmt.Println(themekit.YellowText(upgradeMessage))
+		themekit.Notice(themekit.YellowText(upgradeMessage))
 		confirmationfn, savefn := commands.PrepareConfigurationMigration(directory)
 
 		if confirmationfn() && savefn() == nil {
@@ -284,7 +503,7 @@ func loadThemeClientWithRetry(directory, env string, isRetry bool) (themekit.The
 	}
 
 	if len(config.AccessToken) > 0 {
-		fmt.Println("DEPRECATION WARNING: 'access_token' (in conf.yml) will soon be deprecated. Use 'password' instead, with the same Password value obtained from https://<your-subdomain>.myshopify.com/admin/apps/private/<app_id>")
+		themekit.Notice("DEPRECATION WARNING: 'access_token' (in conf.yml) will soon be deprecated. Use 'password' instead, with the same Password value obtained from https://<your-subdomain>.myshopify.com/admin/apps/private/<app_id>")
 	}
 
 	return themekit.NewThemeClient(config), nil
@@ -300,7 +519,7 @@ func setupAndParseArgs(args []string) (command string, rest []string) {
 	}
 	set := makeFlagSet("")
 	set.Usage = func() {
-		fmt.Println(commandDescription())
+		themekit.Notice(commandDescription())
 	}
 	set.Parse(args)
 
@@ -339,7 +558,7 @@ func verifyCommand(command string, args []string) {
 
 	if len(errors) > 0 {
 		errorMessage := fmt.Sprintf("Invalid Invocation!\n%s", strings.Join(errors, "\n"))
-		fmt.Println(themekit.RedText(errorMessage))
+		themekit.Notice(themekit.RedText(errorMessage))
 		setupAndParseArgs([]string{"--help"})
 		os.Exit(1)
 	}
@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"os"
 	"syscall"
 )
 
@@ -14,12 +15,12 @@ const minFileDescriptors float64 = 2048
 func init() {
 	var rLimit syscall.Rlimit
 	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rLimit); err != nil {
-		fmt.Printf("Could not read max file limit: %s\n", err)
+		fmt.Fprintf(os.Stderr, "Could not read max file limit: %s\n", err)
 	}
 	rLimit.Cur = uint64(math.Max(minFileDescriptors, float64(rLimit.Cur)))
 	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rLimit); err != nil {
-		fmt.Printf("[warning] %s\n", err)
-		fmt.Printf("[warning] could not set file descriptor limits. Themekit will work, but you might encounter issues if your project holds many files. You can set the limits manually using ulimit -n 2048.\n")
+		fmt.Fprintf(os.Stderr, "[warning] %s\n", err)
+		fmt.Fprintf(os.Stderr, "[warning] could not set file descriptor limits. Themekit will work, but you might encounter issues if your project holds many files. You can set the limits manually using ulimit -n 2048.\n")
 	}
 
 }
@@ -0,0 +1,70 @@
+package themekit
+
+import "time"
+
+// maxWaitInQuietPeriods bounds how long pending events are held back when changes keep coming,
+// in multiples of the quiet period
+const maxWaitInQuietPeriods = 10
+
+// CoalesceEvents forwards asset events once no new event has arrived for the quiet period. Only
+// the latest pending event for each asset key is kept, so a burst of saves results in a single
+// upload of the final contents, and an update followed by a removal results in just the removal.
+// Pending events are flushed anyway once they have waited ten quiet periods, so that a file
+// written continuously does not hold back the uploads of the others.
+// Closing the events channel flushes the pending events and closes the returned channel.
+func CoalesceEvents(events chan AssetEvent, quietPeriod time.Duration) chan AssetEvent {
+	coalesced := make(chan AssetEvent)
+	go func() {
+		pending := map[string]AssetEvent{}
+		order := []string{}
+		quiet := time.NewTimer(quietPeriod)
+		stopTimer(quiet)
+		deadline := time.NewTimer(quietPeriod)
+		stopTimer(deadline)
+		flush := func() {
+			stopTimer(quiet)
+			stopTimer(deadline)
+			for _, key := range order {
+				coalesced <- pending[key]
+			}
+			pending = map[string]AssetEvent{}
+			order = []string{}
+		}
+
+		for {
+			select {
+			case event, more := <-events:
+				if !more {
+					flush()
+					close(coalesced)
+					return
+				}
+				if len(order) == 0 {
+					deadline.Reset(maxWaitInQuietPeriods * quietPeriod)
+				}
+				key := event.Asset().Key
+				if _, found := pending[key]; !found {
+					order = append(order, key)
+				}
+				pending[key] = event
+				stopTimer(quiet)
+				quiet.Reset(quietPeriod)
+			case <-quiet.C:
+				flush()
+			case <-deadline.C:
+				flush()
+			}
+		}
+	}()
+	return coalesced
+}
+
+// stopTimer stops a timer and drains its channel, so that it can be reset
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package themekit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalescingBurstsOfEvents(t *testing.T) {
+	events := make(chan AssetEvent)
+	coalesced := CoalesceEvents(events, 20*time.Millisecond)
+
+	go func() {
+		events <- NewUploadEvent(theme.Asset{Key: "layout/theme.liquid", Value: "first"})
+		events <- NewUploadEvent(theme.Asset{Key: "assets/app.js", Value: "app"})
+		events <- NewUploadEvent(theme.Asset{Key: "layout/theme.liquid", Value: "second"})
+		events <- NewRemovalEvent(theme.Asset{Key: "assets/app.js"})
+	}()
+
+	first := <-coalesced
+	assert.Equal(t, "layout/theme.liquid", first.Asset().Key)
+	assert.Equal(t, "second", first.Asset().Value)
+	second := <-coalesced
+	assert.Equal(t, "assets/app.js", second.Asset().Key)
+	assert.Equal(t, Remove, second.Type())
+}
+
+func TestWaitingForTheQuietPeriod(t *testing.T) {
+	events := make(chan AssetEvent)
+	coalesced := CoalesceEvents(events, 50*time.Millisecond)
+
+	started := time.Now()
+	events <- NewUploadEvent(theme.Asset{Key: "layout/theme.liquid", Value: "first"})
+	time.Sleep(30 * time.Millisecond)
+	events <- NewUploadEvent(theme.Asset{Key: "layout/theme.liquid", Value: "second"})
+
+	event := <-coalesced
+	assert.Equal(t, "second", event.Asset().Value)
+	assert.True(t, time.Since(started) >= 80*time.Millisecond, "The quiet period restarts with each event")
+}
+
+func TestClosingFlushesPendingEvents(t *testing.T) {
+	events := make(chan AssetEvent)
+	coalesced := CoalesceEvents(events, time.Hour)
+
+	go func() {
+		events <- NewUploadEvent(theme.Asset{Key: "layout/theme.liquid", Value: "first"})
+		close(events)
+	}()
+
+	keys := []string{}
+	for event := range coalesced {
+		keys = append(keys, event.Asset().Key)
+	}
+	assert.Equal(t, []string{"layout/theme.liquid"}, keys)
+}
+
+func TestFlushingAfterTheMaximumWait(t *testing.T) {
+	events := make(chan AssetEvent)
+	coalesced := CoalesceEvents(events, 20*time.Millisecond)
+	stop := make(chan bool)
+	defer close(stop)
+
+	go func() {
+		events <- NewUploadEvent(theme.Asset{Key: "assets/app.js", Value: "app"})
+		for {
+			select {
+			case <-stop:
+				return
+			case events <- NewUploadEvent(theme.Asset{Key: "assets/log.txt", Value: "busy"}):
+				time.Sleep(5 * time.Millisecond)
+			}
+		}
+	}()
+
+	select {
+	case event := <-coalesced:
+		assert.Equal(t, "assets/app.js", event.Asset().Key)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Pending events were held back while another file kept changing")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Shopify/themekit"
 	"github.com/Shopify/themekit/bucket"
@@ -25,9 +26,20 @@ type Args struct {
 	NotifyFile   string
 	Prefix       string
 	Version      string
+	Source       string
+	Archive      string
+	ThemeName    string
+	ThemeID      int64
 	SetThemeID   bool
+	DryRun       bool
+	Force        bool
+	Replace      bool
+	Lint         bool
+	Sync         bool
 	BucketSize   int
 	RefillRate   int
+	PollInterval time.Duration
+	SyncInterval time.Duration
 	Bucket       *bucket.LeakyBucket
 
 	WorkingDirGetter WorkingDirGetterType
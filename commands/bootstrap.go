@@ -3,8 +3,12 @@ package commands
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/Shopify/themekit"
 	"github.com/Shopify/themekit/atom"
@@ -18,7 +22,7 @@ const (
 	timberFeedPath = "https://github.com/Shopify/Timber/releases.atom"
 )
 
-// BootstrapCommand bootstraps a new theme using Shopify Timber
+// BootstrapCommand bootstraps a new theme using Shopify Timber, or the starter theme in args.Source
 func BootstrapCommand(args Args) chan bool {
 	done := make(chan bool)
 	go func() {
@@ -28,13 +32,90 @@ func BootstrapCommand(args Args) chan bool {
 	return done
 }
 
+// starterSource is where bootstrap finds the starter theme. Versions of a release feed are
+// resolved to archives under zipRoot, while a zip URL or local zip is used as is.
+type starterSource struct {
+	name     string
+	feed     string
+	zipRoot  string
+	zip      string
+	localZip bool
+}
+
+var timberSource = starterSource{name: "Timber", feed: timberFeedPath, zipRoot: themeZipRoot}
+
+func parseStarterSource(spec string) (starterSource, error) {
+	isURL := strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://")
+	switch {
+	case len(spec) == 0:
+		return timberSource, nil
+	case isURL && strings.HasSuffix(spec, "/releases.atom"):
+		root := strings.TrimSuffix(spec, "releases.atom")
+		return starterSource{name: path.Base(root), feed: spec, zipRoot: root + "archive/"}, nil
+	case isURL && strings.HasSuffix(spec, ".zip"):
+		return starterSource{name: strings.TrimSuffix(path.Base(spec), ".zip"), zip: spec}, nil
+	case strings.HasSuffix(strings.ToLower(spec), ".zip"):
+		if _, err := os.Stat(spec); err != nil {
+			return starterSource{}, err
+		}
+		return starterSource{name: strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec)), zip: spec, localZip: true}, nil
+	}
+	return starterSource{}, fmt.Errorf("'%s' is not a release feed, zip URL or local zip", spec)
+}
+
+// absoluteLocalSource resolves a local zip given as a source, since bootstrap changes into the
+// theme directory before reading it
+func absoluteLocalSource(spec string) string {
+	if len(spec) == 0 || strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		return spec
+	}
+	if absolute, err := filepath.Abs(spec); err == nil {
+		return absolute
+	}
+	return spec
+}
+
+// themeName names the created theme after the source, and the version for release feeds
+func (s starterSource) themeName(version, prefix string) string {
+	name := s.name
+	if len(s.feed) > 0 {
+		name = name + "-" + version
+	}
+	if len(prefix) > 0 {
+		name = prefix + "-" + name
+	}
+	return name
+}
+
+func (s starterSource) zipFor(version string) (string, error) {
+	if len(s.feed) == 0 {
+		return s.zip, nil
+	}
+	if version == masterBranch {
+		return s.zipRoot + masterBranch + ".zip", nil
+	}
+
+	feed, err := downloadAtomFeed(s.feed)
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := findReleaseWith(feed, version)
+	if err != nil {
+		return "", err
+	}
+
+	return s.zipRoot + entry.Title + ".zip", nil
+}
+
 func doBootstrap(args Args) chan bool {
+	args.Source = absoluteLocalSource(args.Source)
 	pwd, _ := os.Getwd()
 	if pwd != args.Directory {
 		os.Chdir(args.Directory)
 	}
 
-	zipLocation, err := zipPathForVersion(args.Version)
+	clientForNewTheme, err := createStarterTheme(args)
 	if err != nil {
 		themekit.NotifyError(err)
 		done := make(chan bool)
@@ -42,51 +123,48 @@ func doBootstrap(args Args) chan bool {
 		return done
 	}
 
-	name := "Timber-" + args.Version
-	if len(args.Prefix) > 0 {
-		name = args.Prefix + "-" + name
-	}
-	clientForNewTheme, themeEvents := args.ThemeClient.CreateTheme(name, zipLocation)
-	mergeEvents(args.EventLog, []chan themekit.ThemeEvent{themeEvents})
-	if args.SetThemeID {
-		AddConfiguration(args.Directory, args.Environment, clientForNewTheme.GetConfiguration())
-	}
-
 	os.Chdir(pwd)
 
 	downloadOptions := Args{}
 	downloadOptions.ThemeClient = clientForNewTheme
 	downloadOptions.EventLog = args.EventLog
+	if args.SetThemeID {
+		downloadOptions.Directory = args.Directory
+		downloadOptions.Environment = args.Environment
+	}
 
 	done := DownloadCommand(downloadOptions)
 
 	return done
 }
 
-func zipPath(version string) string {
-	return themeZipRoot + version + ".zip"
-}
-
-func zipPathForVersion(version string) (string, error) {
-	if version == masterBranch {
-		return zipPath(masterBranch), nil
+func createStarterTheme(args Args) (themekit.ThemeClient, error) {
+	source, err := parseStarterSource(args.Source)
+	if err != nil {
+		return args.ThemeClient, err
 	}
+	name := source.themeName(args.Version, args.Prefix)
 
-	feed, err := downloadAtomFeed()
-	if err != nil {
-		return "", err
+	if source.localZip {
+		args.Source = source.zip
+		args.ThemeName = name
+		return createThemeFromSource(args)
 	}
 
-	entry, err := findReleaseWith(feed, version)
+	zipLocation, err := source.zipFor(args.Version)
 	if err != nil {
-		return "", err
+		return args.ThemeClient, err
 	}
-
-	return zipPath(entry.Title), nil
+	clientForNewTheme, themeEvents := args.ThemeClient.CreateTheme(name, zipLocation)
+	mergeEvents(args.EventLog, []chan themekit.ThemeEvent{themeEvents})
+	if args.SetThemeID {
+		AddConfiguration(args.Directory, args.Environment, clientForNewTheme.GetConfiguration())
+	}
+	return clientForNewTheme, nil
 }
 
-func downloadAtomFeed() (atom.Feed, error) {
-	resp, err := http.Get(timberFeedPath)
+func downloadAtomFeed(feedURL string) (atom.Feed, error) {
+	resp, err := http.Get(feedURL)
 	if err != nil {
 		return atom.Feed{}, err
 	}
@@ -113,7 +191,7 @@ func findReleaseWith(feed atom.Feed, version string) (atom.Entry, error) {
 
 func buildInvalidVersionError(feed atom.Feed, version string) error {
 	buff := bytes.NewBuffer([]byte{})
-	buff.WriteString(themekit.RedText("Invalid Timber Version: " + version))
+	buff.WriteString(themekit.RedText("Invalid Version: " + version))
 	buff.WriteString("\nAvailable Versions Are:")
 	buff.WriteString("\n  - master")
 	buff.WriteString("\n  - latest")
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsingStarterSources(t *testing.T) {
+	source, err := parseStarterSource("")
+	assert.Nil(t, err)
+	assert.Equal(t, timberSource, source)
+
+	source, err = parseStarterSource("https://github.com/acme/starter/releases.atom")
+	assert.Nil(t, err)
+	assert.Equal(t, "starter", source.name)
+	assert.Equal(t, "https://github.com/acme/starter/archive/", source.zipRoot)
+	assert.Equal(t, "acme-starter-v2.0", source.themeName("v2.0", "acme"))
+
+	source, err = parseStarterSource("https://example.com/themes/starter.zip")
+	assert.Nil(t, err)
+	zip, err := source.zipFor(LatestRelease)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/themes/starter.zip", zip)
+	assert.Equal(t, "starter", source.themeName(LatestRelease, ""))
+
+	_, err = parseStarterSource("https://example.com/themes")
+	assert.NotNil(t, err)
+}
+
+func TestParsingALocalZipSource(t *testing.T) {
+	file, _ := ioutil.TempFile("", "starter")
+	file.Close()
+	os.Rename(file.Name(), file.Name()+".zip")
+	defer os.Remove(file.Name() + ".zip")
+
+	source, err := parseStarterSource(file.Name() + ".zip")
+	assert.Nil(t, err)
+	assert.True(t, source.localZip)
+
+	_, err = parseStarterSource("missing.zip")
+	assert.NotNil(t, err)
+}
+
+func TestResolvingLocalSourcesBeforeChangingDirectory(t *testing.T) {
+	pwd, _ := os.Getwd()
+	assert.Equal(t, filepath.Join(pwd, "starter.zip"), absoluteLocalSource("./starter.zip"))
+	assert.Equal(t, "https://example.com/starter.zip", absoluteLocalSource("https://example.com/starter.zip"))
+	assert.Equal(t, "", absoluteLocalSource(""))
+}
+
+func TestResolvingTheMasterBranchOfAFeed(t *testing.T) {
+	zip, err := timberSource.zipFor(masterBranch)
+	assert.Nil(t, err)
+	assert.Equal(t, themeZipRoot+"master.zip", zip)
+}
@@ -2,8 +2,10 @@ package commands
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
 )
 
 func drainErrors(errs chan error) {
@@ -16,20 +18,94 @@ func drainErrors(errs chan error) {
 	}
 }
 
+// listAssets fetches the remote asset listing, returning the first error reported while listing
+func listAssets(client themekit.ThemeClient) ([]theme.Asset, error) {
+	results, errs := client.AssetList()
+	failure := make(chan error, 1)
+	go func() {
+		var first error
+		for err := range errs {
+			if first == nil {
+				first = err
+			}
+		}
+		failure <- first
+	}()
+
+	assets := []theme.Asset{}
+	for asset := range results {
+		assets = append(assets, asset)
+	}
+	return assets, <-failure
+}
+
+// listingFailure reports that the remote assets could not be listed
+func listingFailure(err error) themekit.ThemeEvent {
+	return operationEvent{
+		basicEvent: basicEvent{
+			Title:     "Listing Error",
+			EventType: "list",
+			Etype:     "basicEvent",
+			Formatter: func(b basicEvent) string {
+				return themekit.RedText(fmt.Sprintf("Could not list the remote assets: %s", err))
+			},
+		},
+		operation: "list",
+		status:    errorOutcome(err),
+	}
+}
+
 func mergeEvents(dest chan themekit.ThemeEvent, chans []chan themekit.ThemeEvent) {
 	go func() {
 		for _, ch := range chans {
-			var ok = true
-			for ok {
-				if ev, ok := <-ch; ok {
-					dest <- ev
-				}
+			for ev := range ch {
+				dest <- ev
 			}
-			close(ch)
 		}
 	}()
 }
 
+func loadManifest(args Args) *themekit.Manifest {
+	if len(args.Environment) == 0 {
+		return themekit.NewManifest("")
+	}
+	manifest, err := themekit.LoadManifest(args.Directory, args.Environment)
+	if err != nil {
+		themekit.NotifyError(err)
+	}
+	return manifest
+}
+
+// recordSyncs passes events through while recording successful asset operations in the manifest.
+// The returned done channel is signalled once the manifest has been saved.
+func recordSyncs(manifest *themekit.Manifest, done chan bool, events chan themekit.ThemeEvent) (chan bool, chan themekit.ThemeEvent) {
+	recorded := make(chan themekit.ThemeEvent)
+	saved := make(chan bool)
+	go func() {
+		for event := range events {
+			manifest.Record(event)
+			recorded <- event
+		}
+		close(recorded)
+		saveManifest(manifest)
+		saved <- <-done
+	}()
+	return saved, recorded
+}
+
+func saveManifest(manifest *themekit.Manifest) {
+	if err := manifest.Save(); err != nil {
+		themekit.NotifyError(err)
+	}
+}
+
+// isFailure tells whether an event reports a failed operation. The NoOpEvents returned for the
+// keys Shopify ignores are not failures.
+func isFailure(event themekit.ThemeEvent) bool {
+	_, noOp := event.(themekit.NoOpEvent)
+	return !noOp && !event.Successful()
+}
+
 func logEvent(event themekit.ThemeEvent, eventLog chan themekit.ThemeEvent) {
 	go func() {
 		eventLog <- event
@@ -37,11 +113,11 @@ func logEvent(event themekit.ThemeEvent, eventLog chan themekit.ThemeEvent) {
 }
 
 type basicEvent struct {
-	Formatter func(b basicEvent) string
-	EventType string `json:"event_type"`
-	Target    string `json:"target"`
-	Title     string `json:"title"`
-	Etype     string `json:"type"`
+	Formatter func(b basicEvent) string `json:"-"`
+	EventType string                    `json:"event_type"`
+	Target    string                    `json:"target"`
+	Title     string                    `json:"title"`
+	Etype     string                    `json:"type"`
 }
 
 func message(content string) themekit.ThemeEvent {
@@ -68,3 +144,18 @@ func (b basicEvent) Error() error {
 func (b basicEvent) AsJSON() ([]byte, error) {
 	return json.Marshal(b)
 }
+
+// holdBack passes events through, except the ones rejected. Rejecting an event is expected
+// to log why it was held back.
+func holdBack(events chan themekit.AssetEvent, reject func(themekit.AssetEvent) bool) chan themekit.AssetEvent {
+	passed := make(chan themekit.AssetEvent)
+	go func() {
+		for event := range events {
+			if !reject(event) {
+				passed <- event
+			}
+		}
+		close(passed)
+	}()
+	return passed
+}
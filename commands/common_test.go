@@ -0,0 +1,14 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoredKeysAreNotFailures(t *testing.T) {
+	assert.False(t, isFailure(themekit.NoOpEvent{}))
+	assert.False(t, isFailure(themekit.APIAssetEvent{Code: 200}))
+	assert.True(t, isFailure(themekit.APIAssetEvent{Code: 422}))
+}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"bufio"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -64,11 +63,11 @@ func PrepareConfigurationMigration(dir string) (func() bool, func() error) {
 	confirmationFn := func() bool {
 		before, _ := ioutil.ReadFile(environmentLocation)
 		after := env.String()
-		fmt.Println(themekit.YellowText("Compare changes to configuration:"))
-		fmt.Println(themekit.YellowText("Before:\n"), themekit.GreenText(string(before)))
-		fmt.Println(themekit.YellowText("After:\n"), themekit.RedText(after))
+		themekit.Notice(themekit.YellowText("Compare changes to configuration:"))
+		themekit.Notice(themekit.YellowText("Before:\n"), themekit.GreenText(string(before)))
+		themekit.Notice(themekit.YellowText("After:\n"), themekit.RedText(after))
 		reader := bufio.NewReader(os.Stdin)
-		fmt.Println(themekit.YellowText("Does this look correct? (y/n)"))
+		themekit.Notice(themekit.YellowText("Does this look correct? (y/n)"))
 		text, _ := reader.ReadString('\n')
 		return strings.TrimSpace(text) == "y"
 	}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+)
+
+// remoteLookup returns the current remote state of an asset. An error means the
+// state could not be determined, and the operation is refused.
+type remoteLookup func(key string) (asset theme.Asset, exists bool, err error)
+
+// remoteAssetLookup asks Shopify for the asset every time, for long running commands like watch
+func remoteAssetLookup(client themekit.ThemeClient) remoteLookup {
+	return func(key string) (theme.Asset, bool, error) {
+		asset, err := client.Asset(key)
+		if nonFatal, ok := err.(themekit.NonFatalNetworkError); ok && nonFatal.Code == 404 {
+			return asset, false, nil
+		}
+		return asset, err == nil, err
+	}
+}
+
+// remoteListingLookup fetches the asset listing once, the first time it is needed. When the
+// listing fails, every lookup returns its error.
+func remoteListingLookup(client themekit.ThemeClient) remoteLookup {
+	var remote map[string]theme.Asset
+	var err error
+	return func(key string) (theme.Asset, bool, error) {
+		if remote == nil && err == nil {
+			var assets []theme.Asset
+			if assets, err = listAssets(client); err == nil {
+				remote = map[string]theme.Asset{}
+				for _, asset := range assets {
+					remote[asset.Key] = asset
+				}
+			}
+		}
+		if err != nil {
+			return theme.Asset{}, false, err
+		}
+		asset, exists := remote[key]
+		return asset, exists, nil
+	}
+}
+
+// checkConflict returns the event to report instead of performing an operation that could
+// overwrite a remote change. When the remote asset cannot be looked up, the operation is
+// refused since it cannot be shown to be safe.
+func checkConflict(lookup remoteLookup, manifest *themekit.Manifest, event themekit.AssetEvent) (themekit.ThemeEvent, bool) {
+	if _, synced := manifest.Get(event.Asset().Key); !synced {
+		return nil, false
+	}
+	remote, exists, err := lookup(event.Asset().Key)
+	if err != nil {
+		return uncheckedEvent(event, err), true
+	}
+	return themekit.FindConflict(event, remote, exists, manifest)
+}
+
+func uncheckedEvent(event themekit.AssetEvent, err error) themekit.ThemeEvent {
+	return operationEvent{
+		basicEvent: basicEvent{
+			Title:     "Unchecked",
+			EventType: event.Type().String(),
+			Target:    event.Asset().Key,
+			Etype:     "basicEvent",
+			Formatter: func(b basicEvent) string {
+				return fmt.Sprintf(
+					"[%s]Refusing to %s %s: could not check it for remote changes (%s). Use --force to overwrite it",
+					themekit.RedText("unchecked"),
+					themekit.YellowText(b.EventType),
+					themekit.BlueText(b.Target),
+					err,
+				)
+			},
+		},
+		operation: assetOperation(event.Type().String()),
+		status:    errorOutcome(err),
+	}
+}
+
+// guardConflicts forwards the events that can be performed without overwriting remote changes,
+// and logs a conflict for the others.
+func guardConflicts(lookup remoteLookup, manifest *themekit.Manifest, events chan themekit.AssetEvent, eventLog chan themekit.ThemeEvent) chan themekit.AssetEvent {
+	safe := make(chan themekit.AssetEvent)
+	go func() {
+		for event := range events {
+			if conflict, found := checkConflict(lookup, manifest, event); found {
+				logEvent(conflict, eventLog)
+			} else {
+				safe <- event
+			}
+		}
+		close(safe)
+	}()
+	return safe
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListingLookupReturnsListingFailures(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+	client := themekit.NewThemeClient(themekit.Configuration{URL: ts.URL, AccessToken: "abra", BucketSize: 10, RefillRate: 10, MaxAttempts: 1})
+
+	lookup := remoteListingLookup(client)
+	_, exists, err := lookup("layout/theme.liquid")
+	assert.False(t, exists)
+	assert.NotNil(t, err)
+	_, _, err = lookup("templates/index.liquid")
+	assert.NotNil(t, err, "The failure is remembered")
+}
+
+func TestRefusingUploadsThatCannotBeChecked(t *testing.T) {
+	lookup := func(key string) (theme.Asset, bool, error) {
+		return theme.Asset{}, false, errors.New("connection refused")
+	}
+	manifest := themekit.NewManifest("")
+	manifest.Set("layout/theme.liquid", themekit.ManifestEntry{Checksum: "synced"})
+	events := make(chan themekit.AssetEvent)
+	eventLog := make(chan themekit.ThemeEvent)
+	safe := guardConflicts(lookup, manifest, events, eventLog)
+
+	go func() {
+		events <- themekit.NewUploadEvent(theme.Asset{Key: "layout/theme.liquid", Value: "local"})
+		close(events)
+	}()
+
+	refused := <-eventLog
+	assert.False(t, refused.Successful())
+	_, more := <-safe
+	assert.False(t, more, "Nothing is uploaded when the remote asset could not be checked")
+}
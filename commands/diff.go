@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/diff"
+	"github.com/Shopify/themekit/theme"
+)
+
+// DifferencesExitCode is the exit status used by diff when local and remote assets differ. It
+// differs from the status of every failure, so that scripts can tell differences from errors.
+const DifferencesExitCode = 7
+
+const (
+	diffModified   = "modified"
+	diffLocalOnly  = "local only"
+	diffRemoteOnly = "remote only"
+)
+
+type assetDifference struct {
+	AssetKey       string `json:"asset_key"`
+	Status         string `json:"status"`
+	Diff           string `json:"diff,omitempty"`
+	LocalSize      int    `json:"local_size"`
+	RemoteSize     int    `json:"remote_size"`
+	LocalChecksum  string `json:"local_checksum,omitempty"`
+	RemoteChecksum string `json:"remote_checksum,omitempty"`
+	Etype          string `json:"type"`
+}
+
+func (d assetDifference) String() string {
+	switch {
+	case d.Status == diffLocalOnly:
+		return fmt.Sprintf("%s only exists locally (%d bytes)", themekit.BlueText(d.AssetKey), d.LocalSize)
+	case d.Status == diffRemoteOnly:
+		return fmt.Sprintf("%s only exists on Shopify (%d bytes)", themekit.BlueText(d.AssetKey), d.RemoteSize)
+	case len(d.Diff) > 0:
+		return colorizeDiff(d.Diff)
+	default:
+		return fmt.Sprintf(
+			"Binary asset %s differs: local %d bytes (md5 %s), remote %d bytes (md5 %s)",
+			themekit.BlueText(d.AssetKey),
+			d.LocalSize,
+			themekit.GreenText(d.LocalChecksum),
+			d.RemoteSize,
+			themekit.RedText(d.RemoteChecksum),
+		)
+	}
+}
+
+func (d assetDifference) Successful() bool {
+	return true
+}
+
+func (d assetDifference) Error() error {
+	return nil
+}
+
+func (d assetDifference) AsJSON() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+// DiffCommand shows how local files differ from the remote theme
+func DiffCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		root, err := args.WorkingDirGetter()
+		if err != nil {
+			themekit.NotifyError(err)
+		}
+
+		var localAssets, remoteAssets []theme.Asset
+		if len(args.Filenames) == 0 {
+			if remoteAssets, err = listAssets(args.ThemeClient); err != nil {
+				args.EventLog <- listingFailure(err)
+				done <- true
+				return
+			}
+			localAssets = args.ThemeClient.LocalAssets(root)
+		} else {
+			failed := map[string]bool{}
+			for _, filename := range args.Filenames {
+				asset, err := args.ThemeClient.Asset(filename)
+				if err == nil {
+					remoteAssets = append(remoteAssets, asset)
+				} else if nonFatal, ok := err.(themekit.NonFatalNetworkError); !ok || nonFatal.Code != 404 {
+					args.EventLog <- downloadErrorEvent("", filename, err)
+					failed[filename] = true
+				}
+			}
+			for _, asset := range loadNamedAssets(root, args.Filenames) {
+				if !failed[asset.Key] {
+					localAssets = append(localAssets, asset)
+				}
+			}
+		}
+
+		differences, failures := compareAssets(args.ThemeClient.Asset, remoteAssets, localAssets)
+		for _, failure := range failures {
+			args.EventLog <- failure
+		}
+		for _, difference := range differences {
+			args.EventLog <- difference
+		}
+		args.EventLog <- message(fmt.Sprintf("%d asset(s) differ", len(differences)))
+		done <- true
+	}()
+	return done
+}
+
+// compareAssets lists the differences between local and remote assets. Remote contents
+// are only retrieved when the checksums do not already show the assets are the same, and
+// the assets whose contents could not be retrieved are reported as failures instead.
+func compareAssets(retrieve themekit.AssetRetrieval, remoteAssets, localAssets []theme.Asset) ([]assetDifference, []themekit.ThemeEvent) {
+	remote, local := map[string]theme.Asset{}, map[string]theme.Asset{}
+	keys := []string{}
+	for _, asset := range remoteAssets {
+		remote[asset.Key] = asset
+		keys = append(keys, asset.Key)
+	}
+	for _, asset := range localAssets {
+		local[asset.Key] = asset
+		if _, found := remote[asset.Key]; !found {
+			keys = append(keys, asset.Key)
+		}
+	}
+	sort.Strings(keys)
+
+	differences, failures := []assetDifference{}, []themekit.ThemeEvent{}
+	for _, key := range keys {
+		localAsset, isLocal := local[key]
+		remoteAsset, isRemote := remote[key]
+		difference := assetDifference{AssetKey: key, Etype: "assetDifference"}
+		switch {
+		case !isRemote:
+			difference.Status = diffLocalOnly
+			difference.LocalSize = localAsset.Size()
+		case !isLocal:
+			difference.Status = diffRemoteOnly
+			difference.RemoteSize = remoteAsset.Size()
+		default:
+			if remoteAsset.Checksum == localAsset.Digest() {
+				continue
+			}
+			if !remoteAsset.HasContents() {
+				fetched, err := retrieve(key)
+				if err != nil {
+					failures = append(failures, downloadErrorEvent("", key, err))
+					continue
+				}
+				remoteAsset = fetched
+			}
+			if !describeModification(&difference, remoteAsset, localAsset) {
+				continue
+			}
+		}
+		differences = append(differences, difference)
+	}
+	return differences, failures
+}
+
+func describeModification(difference *assetDifference, remote, local theme.Asset) bool {
+	difference.Status = diffModified
+	difference.LocalSize, difference.RemoteSize = local.Size(), remote.Size()
+	if len(local.Value) > 0 && len(remote.Value) > 0 {
+		difference.Diff = diff.Unified("remote/"+remote.Key, "local/"+local.Key, remote.Value, local.Value, diff.DefaultContext)
+		return len(difference.Diff) > 0
+	}
+	difference.LocalChecksum, difference.RemoteChecksum = local.Digest(), remote.Digest()
+	return difference.LocalChecksum != difference.RemoteChecksum
+}
+
+func colorizeDiff(unified string) string {
+	lines := strings.Split(strings.TrimSuffix(unified, "\n"), "\n")
+	for index, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++"):
+			lines[index] = themekit.BlueText(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[index] = themekit.YellowText(line)
+		case strings.HasPrefix(line, "+"):
+			lines[index] = themekit.GreenText(line)
+		case strings.HasPrefix(line, "-"):
+			lines[index] = themekit.RedText(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAssets(t *testing.T) {
+	retrieved := []string{}
+	retrieve := func(filename string) (theme.Asset, error) {
+		retrieved = append(retrieved, filename)
+		return theme.Asset{Key: filename, Value: "remote\n"}, nil
+	}
+	same := theme.Asset{Key: "layout/theme.liquid", Value: "same\n"}
+	local := []theme.Asset{
+		same,
+		{Key: "templates/index.liquid", Value: "local\n"},
+		{Key: "assets/logo.png", Attachment: "bG9jYWw="},
+		{Key: "snippets/local.liquid", Value: "local\n"},
+	}
+	remote := []theme.Asset{
+		{Key: "layout/theme.liquid", Checksum: same.Digest()},
+		{Key: "templates/index.liquid", Checksum: "changed"},
+		{Key: "assets/logo.png", Attachment: "cmVtb3Rl"},
+		{Key: "snippets/remote.liquid", Value: "remote\n"},
+	}
+
+	differences, failures := compareAssets(retrieve, remote, local)
+
+	assert.Equal(t, 0, len(failures))
+	assert.Equal(t, []string{"templates/index.liquid"}, retrieved)
+	assert.Equal(t, 4, len(differences))
+	assert.Equal(t, "assets/logo.png", differences[0].AssetKey)
+	assert.Equal(t, diffModified, differences[0].Status)
+	assert.Equal(t, "", differences[0].Diff)
+	assert.NotEqual(t, differences[0].LocalChecksum, differences[0].RemoteChecksum)
+	assert.Equal(t, diffLocalOnly, differences[1].Status)
+	assert.Equal(t, diffRemoteOnly, differences[2].Status)
+	assert.Equal(t, "templates/index.liquid", differences[3].AssetKey)
+	assert.Equal(t, "--- remote/templates/index.liquid\n+++ local/templates/index.liquid\n@@ -1,1 +1,1 @@\n-remote\n+local\n", differences[3].Diff)
+}
+
+func TestReportingRemoteContentsThatCouldNotBeRetrieved(t *testing.T) {
+	retrieve := func(filename string) (theme.Asset, error) {
+		return theme.Asset{}, errors.New("connection reset")
+	}
+	local := []theme.Asset{{Key: "templates/index.liquid", Value: "local\n"}}
+	remote := []theme.Asset{{Key: "templates/index.liquid", Checksum: "changed"}}
+
+	differences, failures := compareAssets(retrieve, remote, local)
+
+	assert.Equal(t, 0, len(differences), "An asset that could not be fetched is not compared to empty contents")
+	assert.Equal(t, 1, len(failures))
+	assert.False(t, failures[0].Successful())
+}
+
+func TestDiffingNamedFilesThatCouldNotBeFetched(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	dir, _ := ioutil.TempDir("", "diff")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "templates"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "templates", "index.liquid"), []byte("local"), 0644)
+
+	args := DefaultArgs()
+	args.ThemeClient = themekit.NewThemeClient(themekit.Configuration{URL: ts.URL, AccessToken: "abra", MaxAttempts: 1})
+	args.WorkingDirGetter = func() (string, error) { return dir, nil }
+	args.Filenames = []string{"templates/index.liquid"}
+	args.EventLog = make(chan themekit.ThemeEvent)
+
+	done := DiffCommand(args)
+	outcomes := NewOutcomes()
+	for finished := false; !finished; {
+		select {
+		case event := <-args.EventLog:
+			_, isDifference := event.(assetDifference)
+			assert.False(t, isDifference, "The file is not reported as only existing locally")
+			outcomes.Record(event)
+		case <-done:
+			finished = true
+		}
+	}
+	assert.Equal(t, NetworkFailureExitCode, outcomes.ExitCode())
+}
@@ -7,76 +7,153 @@ import (
 	"path/filepath"
 
 	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/bucket"
 	"github.com/Shopify/themekit/theme"
 )
 
+// downloader fetches assets on a pool of workers throttled by a leaky bucket,
+// and reports the results in the order the assets were requested
+type downloader struct {
+	retrieve    themekit.AssetRetrieval
+	bucket      *bucket.LeakyBucket
+	concurrency int
+	root        string
+	manifest    *themekit.Manifest
+	eventLog    chan themekit.ThemeEvent
+}
+
+type downloadResult struct {
+	index    int
+	filename string
+	asset    theme.Asset
+	err      error
+}
+
 // DownloadCommand downloads file(s) from theme
 func DownloadCommand(args Args) (done chan bool) {
 	done = make(chan bool)
-	eventLog := args.EventLog
+	root, err := os.Getwd()
+	if err != nil {
+		themekit.NotifyError(err)
+	}
 
-	if len(args.Filenames) <= 0 {
-		assets, errs := args.ThemeClient.AssetList()
-		go drainErrors(errs)
-		go downloadAllFiles(assets, done, eventLog)
-	} else {
-		go downloadFiles(args.ThemeClient.Asset, args.Filenames, done, eventLog)
+	client := args.ThemeClient
+	d := downloader{
+		retrieve:    client.Asset,
+		bucket:      client.LeakyBucket(),
+		concurrency: client.GetConfiguration().Concurrency,
+		root:        root,
+		manifest:    loadManifest(args),
+		eventLog:    args.EventLog,
 	}
 
+	go func() {
+		filenames := args.Filenames
+		if len(filenames) <= 0 {
+			filenames = remoteAssetKeys(client)
+		}
+		d.bucket.TopUp()
+		d.bucket.StartDripping()
+		d.download(filenames)
+		d.bucket.StopDripping()
+		saveManifest(d.manifest)
+		done <- true
+	}()
+
 	return done
 }
 
-func downloadAllFiles(assets chan theme.Asset, done chan bool, eventLog chan themekit.ThemeEvent) {
-	for {
-		asset, more := <-assets
-		if more {
-			writeToDisk(asset, eventLog)
-		} else {
-			done <- true
-			return
-		}
+func remoteAssetKeys(client themekit.ThemeClient) []string {
+	assets, errs := client.AssetList()
+	go drainErrors(errs)
+
+	keys := []string{}
+	for asset := range assets {
+		keys = append(keys, asset.Key)
 	}
+	return keys
 }
 
-func downloadFiles(retrievalFunction themekit.AssetRetrieval, filenames []string, done chan bool, eventLog chan themekit.ThemeEvent) {
-	for _, filename := range filenames {
-		if asset, err := retrievalFunction(filename); err != nil {
-			handleError(filename, err, eventLog)
-		} else {
-			writeToDisk(asset, eventLog)
+func (d downloader) download(filenames []string) {
+	jobs := make(chan int)
+	results := make(chan downloadResult)
+	concurrency := d.concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for index := range jobs {
+				d.bucket.GetDrop()
+				asset, err := d.retrieve(filenames[index])
+				results <- downloadResult{index: index, filename: filenames[index], asset: asset, err: err}
+			}
+		}()
+	}
+	go func() {
+		for index := range filenames {
+			jobs <- index
+		}
+		close(jobs)
+	}()
+
+	pending := map[int]downloadResult{}
+	next, failed := 0, 0
+	for received := 0; received < len(filenames); received++ {
+		result := <-results
+		pending[result.index] = result
+		for ready, found := pending[next]; found; ready, found = pending[next] {
+			delete(pending, next)
+			next++
+			if !d.report(ready, next, len(filenames)) {
+				failed++
+			}
 		}
 	}
-	done <- true
-	return
+
+	d.eventLog <- message(fmt.Sprintf("Downloaded %d of %d file(s), %d failed", len(filenames)-failed, len(filenames), failed))
 }
 
-func writeToDisk(asset theme.Asset, eventLog chan themekit.ThemeEvent) {
-	dir, err := os.Getwd()
+func (d downloader) report(result downloadResult, position, total int) bool {
+	progress := fmt.Sprintf("[%d/%d] ", position, total)
+	filename, err := "", result.err
+	if err == nil {
+		filename, err = writeToDisk(d.root, result.asset)
+	}
 	if err != nil {
-		themekit.NotifyError(err)
-		return
+		d.eventLog <- downloadErrorEvent(progress, result.filename, err)
+		return false
 	}
 
+	d.manifest.Set(result.asset.Key, themekit.NewManifestEntry(result.asset))
+	d.manifest.SaveBase(result.asset)
+	d.eventLog <- operationEvent{
+		basicEvent: basicEvent{
+			Title:     "FS Event",
+			EventType: "Write",
+			Target:    filename,
+			Etype:     "fsevent",
+			Formatter: func(b basicEvent) string {
+				return themekit.GreenText(fmt.Sprintf("%sSuccessfully wrote %s to disk", progress, b.Target))
+			},
+		},
+		operation: "download",
+		status:    succeeded,
+	}
+	return true
+}
+
+func writeToDisk(dir string, asset theme.Asset) (string, error) {
 	perms, err := os.Stat(dir)
 	if err != nil {
-		themekit.NotifyError(err)
-		return
+		return "", err
 	}
 
 	filename := fmt.Sprintf("%s/%s", dir, asset.Key)
 	err = os.MkdirAll(filepath.Dir(filename), perms.Mode())
 	if err != nil {
-		themekit.NotifyError(err)
-		return
-	}
-
-	file, err := os.Create(filename)
-	if err != nil {
-		themekit.NotifyError(err)
-		return
+		return filename, err
 	}
-	defer file.Sync()
-	defer file.Close()
 
 	var data []byte
 	switch {
@@ -85,47 +162,59 @@ func writeToDisk(asset theme.Asset, eventLog chan themekit.ThemeEvent) {
 	case len(asset.Attachment) > 0:
 		data, err = base64.StdEncoding.DecodeString(asset.Attachment)
 		if err != nil {
-			themekit.NotifyError(fmt.Errorf("Could not decode %s. error: %s", asset.Key, err))
-			return
+			return filename, fmt.Errorf("Could not decode %s. error: %s", asset.Key, err)
 		}
 	}
 
+	file, err := os.Create(filename)
+	if err != nil {
+		return filename, err
+	}
+	defer file.Close()
+
 	if len(data) > 0 {
 		_, err = file.Write(data)
 	}
-
-	if err != nil {
-		themekit.NotifyError(err)
-	} else {
-		event := basicEvent{
-			Title:     "FS Event",
-			EventType: "Write",
-			Target:    filename,
-			Etype:     "fsevent",
-			Formatter: func(b basicEvent) string {
-				return themekit.GreenText(fmt.Sprintf("Successfully wrote %s to disk", b.Target))
-			},
-		}
-		logEvent(event, eventLog)
+	if err == nil {
+		err = file.Sync()
 	}
+	return filename, err
 }
 
 func handleError(filename string, err error, eventLog chan themekit.ThemeEvent) {
+	if _, ok := err.(themekit.NonFatalNetworkError); ok {
+		logEvent(downloadErrorEvent("", filename, err), eventLog)
+	}
+}
+
+func downloadErrorEvent(progress, filename string, err error) themekit.ThemeEvent {
+	failure := operationEvent{operation: "download", status: unreachable}
 	if nonFatal, ok := err.(themekit.NonFatalNetworkError); ok {
-		event := basicEvent{
+		failure.basicEvent = basicEvent{
 			Title:     "Non-Fatal Network Error",
 			EventType: nonFatal.Verb,
 			Target:    filename,
 			Etype:     "fsevent",
 			Formatter: func(b basicEvent) string {
 				return fmt.Sprintf(
-					"[%s] Could not complete %s for %s",
+					"%s[%s] Could not complete %s for %s",
+					progress,
 					themekit.RedText(fmt.Sprintf("%d", nonFatal.Code)),
 					themekit.YellowText(b.EventType),
 					themekit.BlueText(b.Target),
 				)
 			},
 		}
-		logEvent(event, eventLog)
+		return failure
+	}
+	failure.basicEvent = basicEvent{
+		Title:     "Download Error",
+		EventType: "Write",
+		Target:    filename,
+		Etype:     "fsevent",
+		Formatter: func(b basicEvent) string {
+			return fmt.Sprintf("%s%s", progress, themekit.RedText(fmt.Sprintf("Could not download %s: %s", b.Target, err)))
+		},
 	}
+	return failure
 }
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/bucket"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadingFilesInParallel(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "themekit-download")
+	defer os.RemoveAll(dir)
+
+	retrieve := func(filename string) (theme.Asset, error) {
+		if filename == "templates/missing.liquid" {
+			return theme.Asset{}, themekit.NonFatalNetworkError{Code: 404, Verb: "GET", Message: "not found"}
+		}
+		return theme.Asset{Key: filename, Value: "contents of " + filename}, nil
+	}
+	leakyBucket := bucket.NewLeakyBucket(10, 10, 1)
+	leakyBucket.TopUp()
+	eventLog := make(chan themekit.ThemeEvent)
+	d := downloader{
+		retrieve:    retrieve,
+		bucket:      leakyBucket,
+		concurrency: 3,
+		root:        dir,
+		manifest:    themekit.NewManifest(""),
+		eventLog:    eventLog,
+	}
+	filenames := []string{"layout/theme.liquid", "templates/missing.liquid", "templates/index.liquid", "snippets/a.liquid"}
+
+	go d.download(filenames)
+
+	for index, filename := range filenames {
+		event := (<-eventLog).String()
+		assert.True(t, strings.Contains(event, filename), event)
+		assert.True(t, strings.Contains(event, "/4]"), event)
+		if index == 1 {
+			assert.True(t, strings.Contains(event, "404"), event)
+		}
+	}
+	assert.True(t, strings.Contains((<-eventLog).String(), "Downloaded 3 of 4 file(s), 1 failed"))
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "templates", "index.liquid"))
+	assert.Nil(t, err)
+	assert.Equal(t, "contents of templates/index.liquid", string(contents))
+	_, found := d.manifest.Get("snippets/a.liquid")
+	assert.True(t, found)
+}
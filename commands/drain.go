@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shopify/themekit"
+)
+
+// DrainTimeout is how long watch waits, once interrupted, for queued changes to be uploaded
+var DrainTimeout = 30 * time.Second
+
+// pendingJobs keeps track of the events handed to the workers that have not been performed yet
+type pendingJobs struct {
+	mutex sync.Mutex
+	jobs  map[string]themekit.AssetEvent
+	count map[string]int
+}
+
+func newPendingJobs() *pendingJobs {
+	return &pendingJobs{
+		jobs:  map[string]themekit.AssetEvent{},
+		count: map[string]int{},
+	}
+}
+
+// track passes events through, recording them as pending
+func (p *pendingJobs) track(events chan themekit.AssetEvent) chan themekit.AssetEvent {
+	tracked := make(chan themekit.AssetEvent)
+	go func() {
+		for event := range events {
+			key := event.Asset().Key
+			p.mutex.Lock()
+			p.jobs[key] = event
+			p.count[key]++
+			p.mutex.Unlock()
+			tracked <- event
+		}
+		close(tracked)
+	}()
+	return tracked
+}
+
+func (p *pendingJobs) done(event themekit.AssetEvent) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key := event.Asset().Key
+	if p.count[key]--; p.count[key] <= 0 {
+		delete(p.count, key)
+		delete(p.jobs, key)
+	}
+}
+
+func (p *pendingJobs) has(key string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.count[key] > 0
+}
+
+// unsent returns the latest pending event of each asset, sorted by key
+func (p *pendingJobs) unsent() []themekit.AssetEvent {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	keys := []string{}
+	for key := range p.jobs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	events := make([]themekit.AssetEvent, len(keys))
+	for i, key := range keys {
+		events[i] = p.jobs[key]
+	}
+	return events
+}
+
+// drain waits for an interruption, then stops the watchers and gives the workers until the
+// timeout, or a second interruption, to finish the queued jobs. The jobs left are reported.
+func drain(interrupted chan os.Signal, stop chan bool, workers *sync.WaitGroup, pending *pendingJobs, timeout time.Duration, eventLog chan themekit.ThemeEvent) {
+	<-interrupted
+	eventLog <- message(themekit.YellowText("Stopping, waiting for queued changes to be uploaded (interrupt again to quit now)"))
+	close(stop)
+
+	drained := make(chan bool)
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-interrupted:
+	case <-time.After(timeout):
+	}
+
+	unsent := pending.unsent()
+	if len(unsent) > 0 {
+		eventLog <- message(themekit.RedText(fmt.Sprintf("%d changes were not sent:", len(unsent))))
+	}
+	for _, event := range unsent {
+		eventLog <- unsentEvent(event)
+	}
+}
+
+func unsentEvent(event themekit.AssetEvent) themekit.ThemeEvent {
+	return operationEvent{
+		basicEvent: basicEvent{
+			Title:     "Unsent",
+			EventType: event.Type().String(),
+			Target:    event.Asset().Key,
+			Etype:     "basicEvent",
+			Formatter: func(b basicEvent) string {
+				return fmt.Sprintf("  %s %s", themekit.YellowText(b.EventType), themekit.BlueText(b.Target))
+			},
+		},
+		operation: assetOperation(event.Type().String()),
+		status:    skipped,
+	}
+}
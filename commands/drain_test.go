@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainingQueuedJobsWhenInterrupted(t *testing.T) {
+	eventLog := make(chan themekit.ThemeEvent)
+	interrupted := make(chan os.Signal, 1)
+	stop := make(chan bool)
+	pending := newPendingJobs()
+	jobs := make(chan themekit.AssetEvent)
+	queue := pending.track(jobs)
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	performed := []string{}
+	go func() {
+		for event := range queue {
+			performed = append(performed, event.Asset().Key)
+			pending.done(event)
+		}
+		workers.Done()
+	}()
+	go func() {
+		jobs <- themekit.NewUploadEvent(theme.Asset{Key: "layout/theme.liquid", Value: "theme"})
+		<-stop
+		jobs <- themekit.NewUploadEvent(theme.Asset{Key: "assets/app.js", Value: "app"})
+		close(jobs)
+	}()
+
+	finished := make(chan bool)
+	go func() {
+		drain(interrupted, stop, &workers, pending, time.Second, eventLog)
+		close(finished)
+	}()
+	interrupted <- os.Interrupt
+	<-eventLog
+	<-finished
+
+	assert.Equal(t, []string{"layout/theme.liquid", "assets/app.js"}, performed)
+	assert.Equal(t, 0, len(pending.unsent()))
+}
+
+func TestReportingJobsLeftAfterTheTimeout(t *testing.T) {
+	eventLog := make(chan themekit.ThemeEvent)
+	interrupted := make(chan os.Signal, 1)
+	pending := newPendingJobs()
+	jobs := make(chan themekit.AssetEvent)
+	queue := pending.track(jobs)
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		jobs <- themekit.NewRemovalEvent(theme.Asset{Key: "assets/app.js"})
+	}()
+	<-queue
+
+	go drain(interrupted, make(chan bool), &workers, pending, 10*time.Millisecond, eventLog)
+	interrupted <- os.Interrupt
+	<-eventLog
+	<-eventLog
+	unsent := <-eventLog
+	assert.Equal(t, "Unsent", unsent.(operationEvent).Title)
+	assert.Equal(t, "assets/app.js", unsent.(operationEvent).Target)
+
+	outcomes := NewOutcomes()
+	outcomes.Record(unsent)
+	summary, _ := outcomes.Summary()
+	assert.Equal(t, 1, summary.(runSummary).Operations["remove"].Skipped)
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/liquid"
+	"github.com/Shopify/themekit/theme"
+)
+
+type lintProblem struct {
+	AssetKey string `json:"asset_key"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+	Etype    string `json:"type"`
+}
+
+func (l lintProblem) String() string {
+	return fmt.Sprintf("[%s] %s:%d:%d %s", themekit.RedText("lint"), themekit.BlueText(l.AssetKey), l.Line, l.Column, l.Message)
+}
+
+func (l lintProblem) Successful() bool {
+	return false
+}
+
+func (l lintProblem) Error() error {
+	return fmt.Errorf("%s:%d:%d %s", l.AssetKey, l.Line, l.Column, l.Message)
+}
+
+func (l lintProblem) AsJSON() ([]byte, error) {
+	return json.Marshal(l)
+}
+
+// LintCommand checks the syntax of the Liquid files in the local theme, or the named files
+func LintCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		root, err := args.WorkingDirGetter()
+		if err != nil {
+			themekit.NotifyError(err)
+		}
+
+		assets := loadNamedAssets(root, args.Filenames)
+		if len(args.Filenames) == 0 {
+			assets = args.ThemeClient.LocalAssets(root)
+		}
+
+		found := 0
+		for _, asset := range assets {
+			for _, problem := range lintAsset(asset) {
+				args.EventLog <- problem
+				found++
+			}
+		}
+		if found > 0 {
+			args.EventLog <- message(themekit.RedText(fmt.Sprintf("Found %d problem(s)", found)))
+		} else {
+			args.EventLog <- message(themekit.GreenText("No problems found"))
+		}
+		done <- true
+	}()
+	return done
+}
+
+// lintAsset checks the syntax of Liquid assets, other assets have no problems
+func lintAsset(asset theme.Asset) []lintProblem {
+	problems := []lintProblem{}
+	if !strings.HasSuffix(asset.Key, ".liquid") {
+		return problems
+	}
+	for _, err := range liquid.Lint(asset.Value) {
+		problems = append(problems, lintProblem{AssetKey: asset.Key, Line: err.Line, Column: err.Column, Message: err.Message, Etype: "lintProblem"})
+	}
+	return problems
+}
+
+// reportLintProblems logs the problems with an uploaded asset and reports whether there were any
+func reportLintProblems(event themekit.AssetEvent, eventLog chan themekit.ThemeEvent) bool {
+	if event.Type() != themekit.Update {
+		return false
+	}
+	problems := lintAsset(event.Asset())
+	for _, problem := range problems {
+		logEvent(problem, eventLog)
+	}
+	return len(problems) > 0
+}
+
+// guardLint holds back uploads of Liquid files that have syntax problems
+func guardLint(events chan themekit.AssetEvent, eventLog chan themekit.ThemeEvent) chan themekit.AssetEvent {
+	return holdBack(events, func(event themekit.AssetEvent) bool {
+		return reportLintProblems(event, eventLog)
+	})
+}
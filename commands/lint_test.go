@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardingUploadsWithLintProblems(t *testing.T) {
+	events := make(chan themekit.AssetEvent)
+	eventLog := make(chan themekit.ThemeEvent)
+	clean := guardLint(events, eventLog)
+
+	go func() {
+		events <- themekit.NewUploadEvent(theme.Asset{Key: "templates/index.liquid", Value: "{% if a %}"})
+		events <- themekit.NewUploadEvent(theme.Asset{Key: "templates/404.liquid", Value: "{{ 'Not found' }}"})
+		events <- themekit.NewUploadEvent(theme.Asset{Key: "assets/app.js", Value: "{% if"})
+		close(events)
+	}()
+
+	problem := (<-eventLog).(lintProblem)
+	assert.Equal(t, "templates/index.liquid", problem.AssetKey)
+
+	uploaded := []string{}
+	for event := range clean {
+		uploaded = append(uploaded, event.Asset().Key)
+	}
+	assert.Equal(t, []string{"templates/404.liquid", "assets/app.js"}, uploaded)
+}
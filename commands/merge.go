@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/diff"
+	"github.com/Shopify/themekit/theme"
+)
+
+// MergeCommand merges remote changes to text assets into the local files, using the copy of
+// each file from the last sync as the common base
+func MergeCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		root, err := args.WorkingDirGetter()
+		if err != nil {
+			themekit.NotifyError(err)
+		}
+		manifest := loadManifest(args)
+		for _, filename := range args.Filenames {
+			if err := mergeFile(args.ThemeClient, manifest, root, filename, args.EventLog); err != nil {
+				logEvent(message(themekit.RedText(fmt.Sprintf("Could not merge %s: %s", filename, err))), args.EventLog)
+			}
+		}
+		saveManifest(manifest)
+		done <- true
+	}()
+	return done
+}
+
+func mergeFile(client themekit.ThemeClient, manifest *themekit.Manifest, root, filename string, eventLog chan themekit.ThemeEvent) error {
+	local, err := theme.LoadAsset(root, filename)
+	if err != nil {
+		return err
+	}
+	if len(local.Attachment) > 0 {
+		return fmt.Errorf("only text assets can be merged")
+	}
+	base, found := manifest.LoadBase(local.Key)
+	if !found {
+		return fmt.Errorf("there is no copy from the last sync to merge against, download the file first")
+	}
+	remote, err := client.Asset(local.Key)
+	if err != nil {
+		return err
+	}
+	if len(remote.Attachment) > 0 {
+		return fmt.Errorf("the remote asset is not a text asset")
+	}
+
+	result := diff.Merge3(base, local.Value, remote.Value)
+	if err := ioutil.WriteFile(filepath.Join(root, filepath.FromSlash(filename)), []byte(result.String()), 0644); err != nil {
+		return err
+	}
+
+	// The local file now includes the remote changes, so the remote version becomes the new base
+	manifest.Set(remote.Key, themekit.NewManifestEntry(remote))
+	manifest.SaveBase(remote)
+
+	if result.Conflicts > 0 {
+		logEvent(message(themekit.YellowText(fmt.Sprintf("%s has %d conflict(s), resolve them before uploading", local.Key, result.Conflicts))), eventLog)
+	} else {
+		logEvent(message(themekit.GreenText(fmt.Sprintf("Merged remote changes into %s", local.Key))), eventLog)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+)
+
+// NewCommand creates a theme from a local directory or zip and uploads its files
+func NewCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		if _, err := createThemeFromSource(args); err != nil {
+			themekit.NotifyError(err)
+		}
+		done <- true
+	}()
+	return done
+}
+
+// createThemeFromSource returns a client for the created theme
+func createThemeFromSource(args Args) (themekit.ThemeClient, error) {
+	if len(args.Source) == 0 {
+		return args.ThemeClient, fmt.Errorf("a directory or zip to create the theme from is required")
+	}
+	assets, err := loadThemeSource(args.ThemeClient, args.Source)
+	if err != nil {
+		return args.ThemeClient, err
+	}
+	if len(assets) == 0 {
+		return args.ThemeClient, fmt.Errorf("%s does not contain any theme files", args.Source)
+	}
+
+	name := args.ThemeName
+	if len(name) == 0 {
+		name = strings.TrimSuffix(filepath.Base(args.Source), filepath.Ext(args.Source))
+	}
+	created := args.ThemeClient.CreateEmptyTheme(name)
+	if err := logThemeEvent(created, args.EventLog); err != nil {
+		return args.ThemeClient, err
+	}
+
+	client := args.ThemeClient.ForTheme(created.ThemeID)
+	uploadArgs := Args{ThemeClient: client, EventLog: args.EventLog, Bucket: client.LeakyBucket()}
+	// The manifest only describes the project directory, so uploads from anywhere else are not recorded
+	if args.SetThemeID && sameDirectory(args.Source, args.Directory) {
+		uploadArgs.Directory, uploadArgs.Environment = args.Directory, args.Environment
+	}
+	if failed := uploadAssets(uploadArgs, assets); failed > 0 {
+		args.EventLog <- message(themekit.YellowText(fmt.Sprintf("%d file(s) could not be uploaded to %s", failed, name)))
+	}
+
+	if args.SetThemeID {
+		AddConfiguration(args.Directory, args.Environment, client.GetConfiguration())
+	}
+	args.EventLog <- message(themekit.GreenText(fmt.Sprintf("Created %s with id %d from %s", name, created.ThemeID, args.Source)))
+	return client, nil
+}
+
+func loadThemeSource(client themekit.ThemeClient, source string) ([]theme.Asset, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return client.LocalAssets(source), nil
+	}
+	if strings.ToLower(filepath.Ext(source)) != ".zip" {
+		return nil, fmt.Errorf("%s is neither a directory nor a zip", source)
+	}
+	return client.ArchivedAssets(source)
+}
+
+// uploadAssets uploads the assets through a foreman throttled by the client's leaky bucket,
+// and returns the number of uploads that failed
+func uploadAssets(args Args, assets []theme.Asset) int {
+	args.Bucket.TopUp()
+	rawEvents, throttledEvents := prepareChannel(args)
+	done, logs := args.ThemeClient.Process(throttledEvents)
+	done, logs = recordSyncs(loadManifest(args), done, logs)
+
+	go func() {
+		for _, asset := range assets {
+			rawEvents <- themekit.NewUploadEvent(asset)
+		}
+		close(rawEvents)
+	}()
+
+	failed := 0
+	for event := range logs {
+		if isFailure(event) {
+			failed++
+		}
+		args.EventLog <- event
+	}
+	<-done
+	return failed
+}
+
+func sameDirectory(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && a == b
+}
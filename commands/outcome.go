@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Shopify/themekit"
+)
+
+const (
+	// ErrorExitCode is the exit status used when operations failed for another reason, like
+	// credentials Shopify refused. It is the status fatal errors exit with.
+	ErrorExitCode = 1
+	// NetworkFailureExitCode is the exit status used when operations failed because Shopify
+	// could not be reached or responded with a server error
+	NetworkFailureExitCode = themekit.NetworkFailureExitCode
+	// PartialFailureExitCode is the exit status used when some operations failed while others
+	// succeeded, or when they failed for different reasons
+	PartialFailureExitCode = 5
+	// ConflictExitCode is the exit status used when operations were refused because the remote
+	// asset changed since it was last synced
+	ConflictExitCode = 6
+)
+
+type outcomeStatus int
+
+const (
+	succeeded outcomeStatus = iota
+	skipped
+	invalid
+	unreachable
+	conflicted
+	erred
+)
+
+// OperationCounts are the outcomes of one kind of operation
+type OperationCounts struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// operationEvent is a message reporting the outcome of an operation that is not an API call of
+// its own, like writing a downloaded asset, so that it is counted without relying on its wording
+type operationEvent struct {
+	basicEvent
+	operation string
+	status    outcomeStatus
+}
+
+func (o operationEvent) Successful() bool {
+	return o.status == succeeded || o.status == skipped
+}
+
+// Outcomes tracks the outcome of the operations reported by the events of a run, and the exit
+// status called for by what the run found, like a non-empty plan. It is safe for concurrent use.
+type Outcomes struct {
+	mutex      *sync.Mutex
+	operations map[string]*OperationCounts
+	failures   map[outcomeStatus]int
+	found      int
+}
+
+// NewOutcomes returns a tracker that has not seen any operations
+func NewOutcomes() *Outcomes {
+	return &Outcomes{mutex: &sync.Mutex{}, operations: map[string]*OperationCounts{}, failures: map[outcomeStatus]int{}}
+}
+
+// Record counts the outcome of the operation an event reports, events that do not report an
+// operation are ignored
+func (o *Outcomes) Record(event themekit.ThemeEvent) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if status, found := findingStatus(event); found {
+		o.found = status
+		return
+	}
+	operation, status, tracked := classifyOutcome(event)
+	if !tracked {
+		return
+	}
+	counts, found := o.operations[operation]
+	if !found {
+		counts = &OperationCounts{}
+		o.operations[operation] = counts
+	}
+	switch status {
+	case succeeded:
+		counts.Succeeded++
+	case skipped:
+		counts.Skipped++
+	default:
+		counts.Failed++
+		o.failures[status]++
+	}
+}
+
+// Summary returns an event summarising the outcomes, found is false when no operations were seen
+func (o *Outcomes) Summary() (summary themekit.ThemeEvent, found bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if len(o.operations) == 0 {
+		return nil, false
+	}
+	operations := map[string]OperationCounts{}
+	for operation, counts := range o.operations {
+		operations[operation] = *counts
+	}
+	return runSummary{Operations: operations, Etype: "runSummary"}, true
+}
+
+// ExitCode returns the status to exit with. Without failures it is the status called for by what
+// the run found, if anything.
+func (o *Outcomes) ExitCode() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	failed, succeeded := 0, 0
+	for _, counts := range o.operations {
+		failed += counts.Failed
+		succeeded += counts.Succeeded
+	}
+	switch {
+	case failed == 0:
+		return o.found
+	case succeeded > 0 || len(o.failures) > 1:
+		return PartialFailureExitCode
+	case o.failures[invalid] > 0:
+		return InvalidThemeExitCode
+	case o.failures[conflicted] > 0:
+		return ConflictExitCode
+	case o.failures[erred] > 0:
+		return ErrorExitCode
+	default:
+		return NetworkFailureExitCode
+	}
+}
+
+// classifyOutcome describes the operation reported by an event and how it turned out
+func classifyOutcome(event themekit.ThemeEvent) (operation string, status outcomeStatus, tracked bool) {
+	switch e := event.(type) {
+	case themekit.APIAssetEvent:
+		return assetOperation(e.EventType), responseOutcome(e.Code), true
+	case themekit.APIThemeEvent:
+		return "theme", responseOutcome(e.Code), true
+	case themekit.AssetConflictEvent:
+		return assetOperation(e.EventType), conflicted, true
+	case validationProblem, lintProblem:
+		return "validation", invalid, true
+	case operationEvent:
+		return e.operation, e.status, true
+	}
+	return "", succeeded, false
+}
+
+// findingStatus returns the exit status called for by an event reporting something a command
+// found, rather than the outcome of an operation
+func findingStatus(event themekit.ThemeEvent) (int, bool) {
+	switch event.(type) {
+	case plannedChange:
+		return DryRunExitCode, true
+	case assetDifference:
+		return DifferencesExitCode, true
+	}
+	return 0, false
+}
+
+func assetOperation(eventType string) string {
+	if eventType == themekit.Update.String() {
+		return "upload"
+	}
+	return strings.ToLower(eventType)
+}
+
+// errorOutcome tells how an operation that failed with an error turned out
+func errorOutcome(err error) outcomeStatus {
+	if themekit.IsNetworkFailure(err) {
+		return unreachable
+	}
+	return erred
+}
+
+func responseOutcome(code int) outcomeStatus {
+	switch {
+	case code >= 200 && code < 300:
+		return succeeded
+	case code == themekit.ThemeEventErrorCode || code == 429 || code >= 500:
+		return unreachable
+	default:
+		return invalid
+	}
+}
+
+type runSummary struct {
+	Operations map[string]OperationCounts `json:"operations"`
+	Etype      string                     `json:"type"`
+}
+
+func (r runSummary) String() string {
+	names := []string{}
+	for name := range r.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{"Summary:"}
+	for _, name := range names {
+		counts := r.Operations[name]
+		failed := fmt.Sprintf("%d failed", counts.Failed)
+		if counts.Failed > 0 {
+			failed = themekit.RedText(failed)
+		}
+		lines = append(lines, fmt.Sprintf("  %-10s %s succeeded, %s, %d skipped", name, themekit.GreenText(fmt.Sprintf("%d", counts.Succeeded)), failed, counts.Skipped))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r runSummary) Successful() bool {
+	for _, counts := range r.Operations {
+		if counts.Failed > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (r runSummary) Error() error {
+	return nil
+}
+
+func (r runSummary) AsJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarisingOutcomes(t *testing.T) {
+	outcomes := NewOutcomes()
+	_, found := outcomes.Summary()
+	assert.False(t, found, "Nothing happened yet")
+
+	outcomes.Record(themekit.APIAssetEvent{EventType: "Update", Code: 200})
+	outcomes.Record(themekit.APIAssetEvent{EventType: "Update", Code: 422})
+	outcomes.Record(themekit.APIAssetEvent{EventType: "Remove", Code: 200})
+	outcomes.Record(themekit.AssetConflictEvent{EventType: "Update"})
+	outcomes.Record(message("not an operation"))
+
+	summary, found := outcomes.Summary()
+	assert.True(t, found)
+	assert.Equal(t, map[string]OperationCounts{
+		"upload": {Succeeded: 1, Failed: 2},
+		"remove": {Succeeded: 1},
+	}, summary.(runSummary).Operations)
+	assert.False(t, summary.Successful())
+	assert.Equal(t, PartialFailureExitCode, outcomes.ExitCode())
+}
+
+func TestExitCodesForFailures(t *testing.T) {
+	outcomes := NewOutcomes()
+	assert.Equal(t, 0, outcomes.ExitCode())
+	outcomes.Record(plannedChange{Action: planCreate, AssetKey: "assets/app.js"})
+	assert.Equal(t, DryRunExitCode, outcomes.ExitCode(), "What the run found sets the status without failures")
+
+	outcomes = NewOutcomes()
+
+	outcomes.Record(themekit.APIAssetEvent{EventType: "Update", Code: 422})
+	assert.Equal(t, InvalidThemeExitCode, outcomes.ExitCode())
+
+	outcomes = NewOutcomes()
+	outcomes.Record(themekit.APIAssetEvent{EventType: "Update", Code: 503})
+	outcomes.Record(downloadErrorEvent("", "assets/app.js", errors.New("timeout")))
+	assert.Equal(t, NetworkFailureExitCode, outcomes.ExitCode())
+
+	outcomes = NewOutcomes()
+	outcomes.Record(themekit.AssetConflictEvent{EventType: "Update"})
+	assert.Equal(t, ConflictExitCode, outcomes.ExitCode(), "Refusing to overwrite remote changes is a failure")
+
+	outcomes.Record(lintProblem{AssetKey: "layout/theme.liquid"})
+	assert.Equal(t, PartialFailureExitCode, outcomes.ExitCode(), "Failures for different reasons are partial")
+}
+
+func TestCountingDownloadsAndUnsentChanges(t *testing.T) {
+	outcomes := NewOutcomes()
+	outcomes.Record(downloadErrorEvent("", "assets/app.js", themekit.NonFatalNetworkError{Code: 503, Verb: "GET"}))
+	outcomes.Record(unsentEvent(themekit.NewUploadEvent(theme.Asset{Key: "assets/app.css"})))
+	outcomes.Record(basicEvent{Title: "FS Event", EventType: "Write", Formatter: func(b basicEvent) string { return "" }})
+
+	summary, _ := outcomes.Summary()
+	assert.Equal(t, map[string]OperationCounts{
+		"download": {Failed: 1},
+		"upload":   {Skipped: 1},
+	}, summary.(runSummary).Operations, "Only typed events are counted, whatever their wording")
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Shopify/themekit"
+)
+
+// PackageCommand builds a zip of the theme in the working directory that can be uploaded to Shopify
+func PackageCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		archive := args.Archive
+		if len(archive) == 0 {
+			archive = filepath.Join(args.Directory, filepath.Base(args.Directory)+".zip")
+		}
+		keys, err := packageDirectory(args.ThemeClient, args.Directory, archive)
+		if err != nil {
+			themekit.NotifyError(err)
+		} else {
+			args.EventLog <- message(themekit.GreenText(fmt.Sprintf("Packaged %d file(s) into %s", len(keys), archive)))
+		}
+		done <- true
+	}()
+	return done
+}
+
+// UnpackCommand extracts a theme zip into the working directory
+func UnpackCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		for _, archive := range args.Filenames {
+			assets, err := args.ThemeClient.UnpackAssets(archive, args.Directory)
+			if err != nil {
+				themekit.NotifyError(err)
+				continue
+			}
+			args.EventLog <- message(themekit.GreenText(fmt.Sprintf("Unpacked %d file(s) from %s into %s", len(assets), archive, args.Directory)))
+		}
+		done <- true
+	}()
+	return done
+}
+
+func packageDirectory(client themekit.ThemeClient, dir, archive string) ([]string, error) {
+	file, err := os.Create(archive)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := client.PackageAssets(dir, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(archive)
+	}
+	return keys, err
+}
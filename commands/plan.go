@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+)
+
+// DryRunExitCode is the exit status used when a dry run finds changes that would be made
+const DryRunExitCode = 2
+
+const (
+	planCreate = "create"
+	planUpdate = "update"
+	planDelete = "delete"
+)
+
+type plannedChange struct {
+	Action   string `json:"action"`
+	AssetKey string `json:"asset_key"`
+	Size     int    `json:"size"`
+	Etype    string `json:"type"`
+}
+
+func (p plannedChange) String() string {
+	var action string
+	switch p.Action {
+	case planCreate:
+		action = themekit.GreenText(p.Action)
+	case planUpdate:
+		action = themekit.YellowText(p.Action)
+	default:
+		action = themekit.RedText(p.Action)
+	}
+	return fmt.Sprintf("[%s] %s (%d bytes)", action, themekit.BlueText(p.AssetKey), p.Size)
+}
+
+func (p plannedChange) Successful() bool {
+	return true
+}
+
+func (p plannedChange) Error() error {
+	return nil
+}
+
+func (p plannedChange) AsJSON() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// planChanges describes what performing events would do to a theme whose current contents are remoteAssets.
+// Uploads of assets that already match the remote checksum are left out of the plan.
+func planChanges(remoteAssets []theme.Asset, events []themekit.AssetEvent) []plannedChange {
+	remote := map[string]theme.Asset{}
+	for _, asset := range remoteAssets {
+		remote[asset.Key] = asset
+	}
+
+	plan := []plannedChange{}
+	for _, event := range events {
+		asset := event.Asset()
+		remoteAsset, exists := remote[asset.Key]
+		change := plannedChange{AssetKey: asset.Key, Size: asset.Size(), Etype: "plannedChange"}
+		switch {
+		case event.Type() == themekit.Remove:
+			change.Action = planDelete
+			change.Size = remoteAsset.Size()
+		case !exists:
+			change.Action = planCreate
+		case len(remoteAsset.Checksum) > 0 && remoteAsset.Checksum == asset.Digest():
+			continue
+		default:
+			change.Action = planUpdate
+		}
+		plan = append(plan, change)
+	}
+	sort.Sort(byPlannedKey(plan))
+	return plan
+}
+
+type byPlannedKey []plannedChange
+
+func (p byPlannedKey) Len() int {
+	return len(p)
+}
+
+func (p byPlannedKey) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+func (p byPlannedKey) Less(i, j int) bool {
+	return p[i].AssetKey < p[j].AssetKey
+}
+
+// dryRun prints the plan for the events produced by eventsFor instead of sending them to Shopify,
+// The planned changes call for a non-zero exit status.
+func dryRun(args Args, eventsFor func(remoteAssets []theme.Asset) []themekit.AssetEvent) chan bool {
+	done := make(chan bool)
+	go func() {
+		remoteAssets, err := listAssets(args.ThemeClient)
+		if err != nil {
+			args.EventLog <- listingFailure(err)
+			done <- true
+			return
+		}
+		plan := planChanges(remoteAssets, eventsFor(remoteAssets))
+		for _, change := range plan {
+			args.EventLog <- change
+		}
+		args.EventLog <- message(fmt.Sprintf("Dry run: %d change(s) planned", len(plan)))
+		done <- true
+	}()
+	return done
+}
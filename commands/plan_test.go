@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanChanges(t *testing.T) {
+	unchanged := theme.Asset{Key: "layout/theme.liquid", Value: "unchanged"}
+	changed := theme.Asset{Key: "templates/index.liquid", Value: "changed"}
+	created := theme.Asset{Key: "snippets/new.liquid", Value: "new"}
+	remote := []theme.Asset{
+		{Key: "layout/theme.liquid", Checksum: unchanged.Digest()},
+		{Key: "templates/index.liquid", Value: "old"},
+		{Key: "snippets/old.liquid", Value: "goodbye"},
+	}
+	events := []themekit.AssetEvent{
+		themekit.NewUploadEvent(unchanged),
+		themekit.NewUploadEvent(changed),
+		themekit.NewUploadEvent(created),
+		themekit.NewRemovalEvent(theme.Asset{Key: "snippets/old.liquid"}),
+	}
+
+	plan := planChanges(remote, events)
+
+	assert.Equal(t, []plannedChange{
+		{Action: planCreate, AssetKey: "snippets/new.liquid", Size: 3, Etype: "plannedChange"},
+		{Action: planDelete, AssetKey: "snippets/old.liquid", Size: 7, Etype: "plannedChange"},
+		{Action: planUpdate, AssetKey: "templates/index.liquid", Size: 7, Etype: "plannedChange"},
+	}, plan)
+}
+
+func TestDryRunWhenTheListingFails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	args := DefaultArgs()
+	args.ThemeClient = themekit.NewThemeClient(themekit.Configuration{URL: ts.URL, AccessToken: "abra", MaxAttempts: 1})
+	args.EventLog = make(chan themekit.ThemeEvent)
+
+	done := dryRun(args, func(remoteAssets []theme.Asset) []themekit.AssetEvent {
+		t.Error("Nothing is planned without the listing")
+		return nil
+	})
+	outcomes := NewOutcomes()
+	for finished := false; !finished; {
+		select {
+		case event := <-args.EventLog:
+			outcomes.Record(event)
+		case <-done:
+			finished = true
+		case <-time.After(time.Second):
+			t.Fatal("The dry run did not finish")
+		}
+	}
+	assert.Equal(t, ErrorExitCode, outcomes.ExitCode())
+}
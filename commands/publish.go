@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+)
+
+// PublishCommand makes the environment's theme the live theme of the shop, remembering
+// the theme it replaces so the publish can be rolled back
+func PublishCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		if err := publish(args); err != nil {
+			themekit.NotifyError(err)
+		}
+		done <- true
+	}()
+	return done
+}
+
+// RollbackCommand re-publishes the theme that was live before the environment's last publish
+func RollbackCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		if err := rollback(args); err != nil {
+			themekit.NotifyError(err)
+		}
+		done <- true
+	}()
+	return done
+}
+
+func publish(args Args) error {
+	client := args.ThemeClient
+	themeID := client.GetConfiguration().ThemeID
+	if themeID == 0 {
+		return fmt.Errorf("the %s environment has no theme_id, there is no theme to publish", args.Environment)
+	}
+
+	themes, err := client.Themes()
+	if err != nil {
+		return err
+	}
+	target, found := findTheme(themes, func(t theme.Theme) bool { return t.ID == themeID })
+	if !found {
+		return fmt.Errorf("theme %d does not exist on this shop", themeID)
+	}
+	if target.Role == "main" {
+		args.EventLog <- message(fmt.Sprintf("%s is already the live theme", target.Name))
+		return nil
+	}
+	if !target.Previewable {
+		return fmt.Errorf("%s is still being processed, try again once it can be previewed", target.Name)
+	}
+
+	if args.Replace {
+		if failed := replaceBeforePublishing(args); failed > 0 {
+			return fmt.Errorf("%d file(s) could not be uploaded, %s was not published", failed, target.Name)
+		}
+	}
+
+	live, _ := findTheme(themes, isLive)
+	if err := switchLiveTheme(args, target, live); err != nil {
+		return err
+	}
+	args.EventLog <- message(themekit.GreenText(fmt.Sprintf("%s is now live, run 'theme rollback' to restore %s", target.Name, live.Name)))
+	return nil
+}
+
+func rollback(args Args) error {
+	record, found, err := themekit.LoadPublishRecord(args.Directory, args.Environment)
+	if err != nil {
+		return err
+	}
+	if !found || record.PreviousThemeID == 0 {
+		return fmt.Errorf("no previously live theme was recorded for the %s environment", args.Environment)
+	}
+
+	themes, err := args.ThemeClient.Themes()
+	if err != nil {
+		return err
+	}
+	previous, found := findTheme(themes, func(t theme.Theme) bool { return t.ID == record.PreviousThemeID })
+	if !found {
+		return fmt.Errorf("theme %d, which was live before the last publish, no longer exists", record.PreviousThemeID)
+	}
+	if previous.Role == "main" {
+		args.EventLog <- message(fmt.Sprintf("%s is already the live theme", previous.Name))
+		return nil
+	}
+
+	live, _ := findTheme(themes, isLive)
+	if err := switchLiveTheme(args, previous, live); err != nil {
+		return err
+	}
+	args.EventLog <- message(themekit.GreenText(fmt.Sprintf("%s is live again", previous.Name)))
+	return nil
+}
+
+// switchLiveTheme publishes the target and records the theme it replaced. Recording
+// the replaced theme after a rollback lets a second rollback undo the first.
+func switchLiveTheme(args Args, target, live theme.Theme) error {
+	if err := logThemeEvent(args.ThemeClient.PublishTheme(target.ID), args.EventLog); err != nil {
+		return err
+	}
+	record := themekit.PublishRecord{
+		ThemeID:         target.ID,
+		PreviousThemeID: live.ID,
+		PublishedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+	return record.Save(args.Directory, args.Environment)
+}
+
+// replaceBeforePublishing runs a full replace and returns the number of files that failed,
+// counting the files held back because they are not valid JSON
+func replaceBeforePublishing(args Args) int {
+	args.Filenames = []string{}
+	done, logs, queued := replace(args)
+	failed := 0
+	for event := range logs {
+		if isFailure(event) {
+			failed++
+		}
+		args.EventLog <- event
+	}
+	<-done
+	return failed + (<-queued).heldBack
+}
+
+func isLive(t theme.Theme) bool {
+	return t.Role == "main"
+}
+
+func findTheme(themes []theme.Theme, matches func(theme.Theme) bool) (theme.Theme, bool) {
+	for _, t := range themes {
+		if matches(t) {
+			return t, true
+		}
+	}
+	return theme.Theme{}, false
+}
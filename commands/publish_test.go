@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotPublishingWhenReplacingHeldBackInvalidJSON(t *testing.T) {
+	published := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/admin/themes.json":
+			w.Write([]byte(`{"themes":[{"id":1,"name":"Live","role":"main","previewable":true},{"id":2,"name":"Next","role":"unpublished","previewable":true}]}`))
+		case r.URL.Path == "/admin/themes/2.json" && r.Method == "PUT":
+			published++
+			w.Write([]byte(`{"theme":{"id":2,"name":"Next","role":"main","previewable":true}}`))
+		case r.URL.Path == "/admin/themes/2/assets.json" && r.Method == "GET":
+			w.Write([]byte(`{"assets":[]}`))
+		default:
+			w.Write([]byte(`{"asset":{"key":"layout/theme.liquid"}}`))
+		}
+	}))
+	defer ts.Close()
+	defer trustServer(ts)()
+
+	dir, _ := ioutil.TempDir("", "publish")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "layout"), 0755)
+	os.MkdirAll(filepath.Join(dir, "templates"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "layout", "theme.liquid"), []byte("{{ content_for_layout }}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "templates", "index.json"), []byte(`{"sections": `), 0644)
+	previous, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(previous)
+
+	config := themekit.Configuration{Domain: ts.Listener.Addr().String(), AccessToken: "abra", ThemeID: 2, MaxAttempts: 1}
+	config.URL = config.AdminURL() + "/themes/2"
+	args := DefaultArgs()
+	args.Directory = dir
+	args.Replace = true
+	args.ThemeClient = themekit.NewThemeClient(config)
+	args.EventLog = make(chan themekit.ThemeEvent)
+	go func() {
+		for range args.EventLog {
+		}
+	}()
+
+	err := publish(args)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, published, "A partially uploaded theme is not made live")
+}
+
+// trustServer makes new theme clients trust the certificate of a TLS test server, since admin
+// requests are always made over https. The returned function restores the default transport.
+func trustServer(ts *httptest.Server) func() {
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = ts.Client().Transport
+	return func() { http.DefaultTransport = defaultTransport }
+}
@@ -10,8 +10,19 @@ import (
 
 // RemoveCommand removes file(s) from theme
 func RemoveCommand(args Args) chan bool {
+	if args.DryRun {
+		return dryRun(args, func(remoteAssets []theme.Asset) []themekit.AssetEvent {
+			events := []themekit.AssetEvent{}
+			for _, filename := range args.Filenames {
+				events = append(events, themekit.NewRemovalEvent(theme.Asset{Key: filename}))
+			}
+			return events
+		})
+	}
+
 	events := make(chan themekit.AssetEvent)
 	done, logs := args.ThemeClient.Process(events)
+	done, logs = recordSyncs(loadManifest(args), done, logs)
 
 	mergeEvents(args.EventLog, []chan themekit.ThemeEvent{logs})
 
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/Shopify/themekit"
@@ -9,48 +10,197 @@ import (
 
 // ReplaceCommand overwrite theme file(s)
 func ReplaceCommand(args Args) chan bool {
+	if args.DryRun {
+		return dryRun(args, func(remoteAssets []theme.Asset) []themekit.AssetEvent {
+			return replaceEvents(args.ThemeClient, args.Filenames, remoteAssets)
+		})
+	}
+
+	done, logs, queued := replace(args)
+	if len(args.Filenames) > 0 {
+		mergeEvents(args.EventLog, []chan themekit.ThemeEvent{logs})
+		return done
+	}
+	summarised := make(chan bool)
+	go func() {
+		summary := replaceSummary{}
+		for event := range logs {
+			summary.record(event)
+			args.EventLog <- event
+		}
+		<-done
+		unsent := <-queued
+		summary.skipped, summary.heldBack = unsent.skipped, unsent.heldBack
+		args.EventLog <- message(summary.String())
+		summarised <- true
+	}()
+	return summarised
+}
+
+// replace uploads the local files in place of the remote ones. Once every file has been queued,
+// a summary counting the files skipped because they are unchanged, and the files held back
+// because they are not valid JSON, is sent on queued.
+func replace(args Args) (done chan bool, logs chan themekit.ThemeEvent, queued chan replaceSummary) {
 	rawEvents, throttledEvents := prepareChannel(args)
-	done, logs := args.ThemeClient.Process(throttledEvents)
-	mergeEvents(args.EventLog, []chan themekit.ThemeEvent{logs})
-	enqueueEvents(args.ThemeClient, args.Filenames, rawEvents)
-	return done
+	manifest := loadManifest(args)
+	done, logs = args.ThemeClient.Process(throttledEvents)
+	done, logs = recordSyncs(manifest, done, logs)
+
+	root, _ := os.Getwd()
+	events := make(chan themekit.AssetEvent)
+	queued = make(chan replaceSummary, 1)
+	skipped := enqueueEvents(args, manifest, events)
+	go func() {
+		held := 0
+		guarded := holdBack(events, func(event themekit.AssetEvent) bool {
+			invalid := reportJSONProblems(root, event, args.EventLog)
+			if invalid {
+				held++
+			}
+			return invalid
+		})
+		for event := range guarded {
+			rawEvents <- event
+		}
+		close(rawEvents)
+		queued <- replaceSummary{skipped: <-skipped, heldBack: held}
+	}()
+	return done, logs, queued
 }
 
-func enqueueEvents(client themekit.ThemeClient, filenames []string, events chan themekit.AssetEvent) {
+// enqueueEvents sends the events of the replace, and then the number of unchanged files that
+// are skipped
+func enqueueEvents(args Args, manifest *themekit.Manifest, events chan themekit.AssetEvent) chan int {
 	root, _ := os.Getwd()
-	if len(filenames) == 0 {
-		go fullReplace(client.AssetListSync(), client.LocalAssets(root), events)
-		return
+	skipped := make(chan int, 1)
+	if len(args.Filenames) == 0 {
+		go func() {
+			remoteAssets, err := listAssets(args.ThemeClient)
+			if err != nil {
+				themekit.NotifyError(err)
+				close(events)
+				skipped <- 0
+				return
+			}
+			localAssets := args.ThemeClient.LocalAssets(root)
+			recordUnchanged(manifest, remoteAssets, localAssets)
+			skipped <- fullReplace(remoteAssets, localAssets, events)
+		}()
+		return skipped
 	}
 	go func() {
-		for _, filename := range filenames {
-			asset, err := theme.LoadAsset(root, filename)
-			if err == nil {
-				events <- themekit.NewUploadEvent(asset)
-			}
+		for _, asset := range loadNamedAssets(root, args.Filenames) {
+			events <- themekit.NewUploadEvent(asset)
 		}
 		close(events)
+		skipped <- 0
 	}()
+	return skipped
 }
 
-// fullReplace takes slices with assets both from the local filesystem and the remote server and translates them
-// into a suitable set of events that updates the remote site to the local state.
-func fullReplace(remoteAssets, localAssets []theme.Asset, events chan themekit.AssetEvent) {
-	assetsActions := map[string]themekit.AssetEvent{}
-	generateActions := func(assets []theme.Asset, assetEventFn func(asset theme.Asset) themekit.SimpleAssetEvent) {
-		for _, asset := range assets {
-			assetsActions[asset.Key] = assetEventFn(asset)
+// recordUnchanged notes in the manifest the local assets that already match the remote theme,
+// since a full replace will not upload them.
+func recordUnchanged(manifest *themekit.Manifest, remoteAssets, localAssets []theme.Asset) {
+	remote := map[string]theme.Asset{}
+	for _, asset := range remoteAssets {
+		remote[asset.Key] = asset
+	}
+	for _, asset := range localAssets {
+		if remoteAsset, found := remote[asset.Key]; found && remoteAsset.Checksum == asset.Digest() {
+			manifest.Set(asset.Key, themekit.NewManifestEntry(remoteAsset))
+			manifest.SaveBase(asset)
+		}
+	}
+}
+
+func replaceEvents(client themekit.ThemeClient, filenames []string, remoteAssets []theme.Asset) []themekit.AssetEvent {
+	root, _ := os.Getwd()
+	events := []themekit.AssetEvent{}
+	if len(filenames) == 0 {
+		actions, _ := replaceActions(remoteAssets, client.LocalAssets(root))
+		for _, event := range actions {
+			events = append(events, event)
+		}
+		return events
+	}
+	for _, asset := range loadNamedAssets(root, filenames) {
+		events = append(events, themekit.NewUploadEvent(asset))
+	}
+	return events
+}
+
+func loadNamedAssets(root string, filenames []string) []theme.Asset {
+	assets := []theme.Asset{}
+	for _, filename := range filenames {
+		asset, err := theme.LoadAsset(root, filename)
+		if err == nil {
+			assets = append(assets, asset)
 		}
 	}
-	generateActions(remoteAssets, themekit.NewRemovalEvent)
-	generateActions(localAssets, themekit.NewUploadEvent)
+	return assets
+}
+
+// replaceSummary counts the outcome of a full replace. Unchanged files are skipped, and files
+// that are not valid JSON are held back and count as failures.
+type replaceSummary struct {
+	uploaded, skipped, removed, failed, heldBack int
+}
+
+// record counts the outcome of an operation performed by the replace
+func (s *replaceSummary) record(event themekit.ThemeEvent) {
+	operation, status, tracked := classifyOutcome(event)
+	switch {
+	case !tracked || status == skipped:
+	case status != succeeded:
+		s.failed++
+	case operation == "remove":
+		s.removed++
+	default:
+		s.uploaded++
+	}
+}
+
+func (s replaceSummary) String() string {
+	return fmt.Sprintf(
+		"Replace summary: %s uploaded, %s skipped, %s removed, %s failed",
+		themekit.GreenText(s.uploaded),
+		themekit.YellowText(s.skipped),
+		themekit.RedText(s.removed),
+		themekit.RedText(s.failed+s.heldBack),
+	)
+}
+
+// fullReplace takes slices with assets both from the local filesystem and the remote server and translates them
+// into a suitable set of events that updates the remote site to the local state. Local assets whose digest matches
+// the remote checksum are left alone, and their number is returned.
+func fullReplace(remoteAssets, localAssets []theme.Asset, events chan themekit.AssetEvent) int {
+	assetsActions, skipped := replaceActions(remoteAssets, localAssets)
 	go func() {
 		for _, event := range assetsActions {
 			events <- event
 		}
 		close(events)
 	}()
+	return skipped
+}
 
+func replaceActions(remoteAssets, localAssets []theme.Asset) (map[string]themekit.AssetEvent, int) {
+	skipped := 0
+	assetsActions := map[string]themekit.AssetEvent{}
+	remoteChecksums := map[string]string{}
+	for _, asset := range remoteAssets {
+		remoteChecksums[asset.Key] = asset.Checksum
+		assetsActions[asset.Key] = themekit.NewRemovalEvent(asset)
+	}
+	for _, asset := range localAssets {
+		if checksum := remoteChecksums[asset.Key]; len(checksum) > 0 && checksum == asset.Digest() {
+			delete(assetsActions, asset.Key)
+			skipped++
+			continue
+		}
+		assetsActions[asset.Key] = themekit.NewUploadEvent(asset)
+	}
+	return assetsActions, skipped
 }
 
 func prepareChannel(args Args) (rawEvents, throttledEvents chan themekit.AssetEvent) {
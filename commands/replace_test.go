@@ -1,6 +1,11 @@
 package commands
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -56,3 +61,64 @@ func TestFullReplace(t *testing.T) {
 		assert.Equal(t, len(d.expectedEvents), eventCount, "Did not get the expected number of events!")
 	}
 }
+
+func TestReplaceActionsSkipsUnchangedAssets(t *testing.T) {
+	local := []theme.Asset{
+		{Key: "layout/theme.liquid", Value: "unchanged"},
+		{Key: "templates/index.liquid", Value: "changed"},
+		{Key: "snippets/new.liquid", Value: "new"},
+	}
+	remote := []theme.Asset{
+		{Key: "layout/theme.liquid", Checksum: local[0].Digest()},
+		{Key: "templates/index.liquid", Checksum: "d41d8cd98f00b204e9800998ecf8427e"},
+		{Key: "snippets/old.liquid", Checksum: "d41d8cd98f00b204e9800998ecf8427e"},
+	}
+
+	actions, skipped := replaceActions(remote, local)
+
+	assert.Equal(t, 3, len(actions))
+	assert.Nil(t, actions["layout/theme.liquid"])
+	assert.Equal(t, themekit.Update, actions["templates/index.liquid"].Type())
+	assert.Equal(t, themekit.Update, actions["snippets/new.liquid"].Type())
+	assert.Equal(t, themekit.Remove, actions["snippets/old.liquid"].Type())
+	assert.Equal(t, 1, skipped)
+}
+
+func TestSummarisingCompletedOperations(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"assets":[{"key":"snippets/old.liquid","checksum":"d41d8cd98f00b204e9800998ecf8427e"}]}`))
+		case "PUT":
+			w.WriteHeader(422)
+			w.Write([]byte(`{"errors":{"asset":["is invalid"]}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+	dir, _ := ioutil.TempDir("", "replace")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "layout"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "layout", "theme.liquid"), []byte("{{ content_for_layout }}"), 0644)
+	previous, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(previous)
+
+	args := DefaultArgs()
+	args.Directory = dir
+	args.ThemeClient = themekit.NewThemeClient(themekit.Configuration{URL: ts.URL, AccessToken: "abra", MaxAttempts: 1})
+	args.EventLog = make(chan themekit.ThemeEvent)
+
+	done := ReplaceCommand(args)
+	var last themekit.ThemeEvent
+	for finished := false; !finished; {
+		select {
+		case event := <-args.EventLog:
+			last = event
+		case <-done:
+			finished = true
+		}
+	}
+	assert.Equal(t, replaceSummary{removed: 1, failed: 1}.String(), last.String(), "The summary counts what happened, and comes before done")
+}
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+)
+
+// DefaultSyncInterval is how often watch -sync looks for changes made on Shopify
+const DefaultSyncInterval = 10 * time.Second
+
+// suppressedWrites remembers what sync wrote to the working directory, so that the filesystem
+// events it causes are not uploaded back. Removed files are remembered with an empty digest.
+type suppressedWrites struct {
+	mutex   sync.Mutex
+	digests map[string]string
+}
+
+func newSuppressedWrites() *suppressedWrites {
+	return &suppressedWrites{digests: map[string]string{}}
+}
+
+func (s *suppressedWrites) wrote(asset theme.Asset) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.digests[asset.Key] = asset.Digest()
+}
+
+func (s *suppressedWrites) removed(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.digests[key] = ""
+}
+
+// matches reports whether an event only reflects what sync itself wrote. What was written is
+// forgotten at the first event for the asset, so later local changes are uploaded.
+func (s *suppressedWrites) matches(event themekit.AssetEvent) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	digest, found := s.digests[event.Asset().Key]
+	if !found {
+		return false
+	}
+	delete(s.digests, event.Asset().Key)
+	if event.Type() == themekit.Remove {
+		return len(digest) == 0
+	}
+	return len(digest) > 0 && digest == event.Asset().Digest()
+}
+
+// remoteSync pulls the assets changed on Shopify, for instance in the online editor, into the
+// working directory. Assets changed on both sides are reported as conflicts and left alone.
+type remoteSync struct {
+	list       func() ([]theme.Asset, error)
+	retrieve   themekit.AssetRetrieval
+	ignore     func(path string) bool
+	root       string
+	manifest   *themekit.Manifest
+	pending    *pendingJobs
+	suppressed *suppressedWrites
+	reported   map[string]string
+	eventLog   chan themekit.ThemeEvent
+}
+
+func newRemoteSync(client themekit.ThemeClient, args Args, manifest *themekit.Manifest, pending *pendingJobs, suppressed *suppressedWrites) *remoteSync {
+	config := client.GetConfiguration()
+	filter := themekit.NewEventFilterFromPatternsAndFiles(config.IgnoredFiles, config.Ignores)
+	return &remoteSync{
+		list:       func() ([]theme.Asset, error) { return listAssets(client) },
+		retrieve:   client.Asset,
+		ignore:     filter.MatchesFilter,
+		root:       args.Directory,
+		manifest:   manifest,
+		pending:    pending,
+		suppressed: suppressed,
+		reported:   map[string]string{},
+		eventLog:   args.EventLog,
+	}
+}
+
+// run polls Shopify every interval until stop is closed
+func (s *remoteSync) run(interval time.Duration, stop chan bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.poll()
+		}
+	}
+}
+
+func (s *remoteSync) poll() {
+	synced := map[string]themekit.ManifestEntry{}
+	for _, key := range s.manifest.Keys() {
+		synced[key], _ = s.manifest.Get(key)
+	}
+
+	assets, err := s.list()
+	if err != nil {
+		logEvent(message(themekit.RedText(fmt.Sprintf("Could not check Shopify for changes: %s", err))), s.eventLog)
+		return
+	}
+
+	remote := map[string]theme.Asset{}
+	for _, asset := range assets {
+		remote[asset.Key] = asset
+		entry, found := synced[asset.Key]
+		if (!found || !entry.Matches(asset)) && s.unchangedSince(asset.Key, entry, found) {
+			s.pull(asset, entry, found)
+		}
+	}
+	removed := []string{}
+	for key := range synced {
+		if _, exists := remote[key]; !exists && s.unchangedSince(key, synced[key], true) {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		s.pullRemoval(key, synced[key])
+	}
+	saveManifest(s.manifest)
+}
+
+// unchangedSince tells whether an asset can be synced: it is not ignored, it has no local change
+// waiting to be uploaded, and it was not synced by an upload while the listing was fetched.
+func (s *remoteSync) unchangedSince(key string, entry themekit.ManifestEntry, found bool) bool {
+	if s.ignore(s.localPath(key)) || s.pending.has(key) {
+		return false
+	}
+	current, stillFound := s.manifest.Get(key)
+	return stillFound == found && current == entry
+}
+
+func (s *remoteSync) pull(remote theme.Asset, entry themekit.ManifestEntry, found bool) {
+	local, exists := s.localAsset(remote.Key)
+	if exists && len(remote.Checksum) > 0 && local.Digest() == remote.Checksum {
+		s.manifest.Set(remote.Key, themekit.NewManifestEntry(remote))
+		s.manifest.SaveBase(local)
+		return
+	}
+	if exists != found || (exists && local.Digest() != entry.Checksum) {
+		s.reportConflict(local, exists, remote, true, entry)
+		return
+	}
+
+	asset, err := s.retrieve(remote.Key)
+	if err != nil {
+		logEvent(downloadErrorEvent("", remote.Key, err), s.eventLog)
+		return
+	}
+	if saved, stillExists := s.localAsset(remote.Key); stillExists != exists || saved.Digest() != local.Digest() {
+		// saved locally while the asset was downloaded
+		s.reportConflict(saved, stillExists, remote, true, entry)
+		return
+	}
+	s.suppressed.wrote(asset)
+	if _, err = writeToDisk(s.root, asset); err != nil {
+		logEvent(downloadErrorEvent("", remote.Key, err), s.eventLog)
+		return
+	}
+	s.manifest.Set(asset.Key, themekit.NewManifestEntry(asset))
+	s.manifest.SaveBase(asset)
+	logEvent(pulledEvent("Write", asset.Key, "Pulled %s from Shopify"), s.eventLog)
+}
+
+func (s *remoteSync) pullRemoval(key string, entry themekit.ManifestEntry) {
+	local, exists := s.localAsset(key)
+	if exists && local.Digest() != entry.Checksum {
+		s.reportConflict(local, true, theme.Asset{Key: key}, false, entry)
+		return
+	}
+	if exists {
+		s.suppressed.removed(key)
+		if err := os.Remove(s.localPath(key)); err != nil {
+			logEvent(downloadErrorEvent("", key, err), s.eventLog)
+			return
+		}
+	}
+	s.manifest.Delete(key)
+	s.manifest.RemoveBase(key)
+	logEvent(pulledEvent("Remove", key, "Removed %s, it was deleted from Shopify"), s.eventLog)
+}
+
+// reportConflict logs a conflict once for each remote version of an asset
+func (s *remoteSync) reportConflict(local theme.Asset, localExists bool, remote theme.Asset, remoteExists bool, entry themekit.ManifestEntry) {
+	version := remote.UpdatedAt + remote.Checksum
+	if seen, found := s.reported[remote.Key]; found && seen == version {
+		return
+	}
+	s.reported[remote.Key] = version
+
+	conflict := themekit.AssetConflictEvent{
+		AssetKey:  remote.Key,
+		EventType: "Download",
+		Remote:    themekit.AssetVersion{Checksum: remote.Checksum, UpdatedAt: remote.UpdatedAt, Size: remote.Size(), Exists: remoteExists},
+		Synced:    entry,
+		Etype:     "AssetConflictEvent",
+	}
+	if localExists {
+		conflict.Local = themekit.AssetVersion{Checksum: local.Digest(), Size: local.Size(), Exists: true}
+	}
+	logEvent(conflict, s.eventLog)
+}
+
+func (s *remoteSync) localPath(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+func (s *remoteSync) localAsset(key string) (theme.Asset, bool) {
+	asset, err := theme.LoadAsset(s.root, filepath.FromSlash(key))
+	asset.Key = key
+	return asset, err == nil
+}
+
+func pulledEvent(eventType, key, format string) themekit.ThemeEvent {
+	return basicEvent{
+		Title:     "FS Event",
+		EventType: eventType,
+		Target:    key,
+		Etype:     "fsevent",
+		Formatter: func(b basicEvent) string {
+			return themekit.GreenText(fmt.Sprintf(format, b.Target))
+		},
+	}
+}
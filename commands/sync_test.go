@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func syncFixture(remote map[string]string) (*remoteSync, string) {
+	dir, _ := ioutil.TempDir("", "themekit-sync")
+	os.MkdirAll(filepath.Join(dir, "snippets"), 0755)
+	listing := func() ([]theme.Asset, error) {
+		assets := []theme.Asset{}
+		for key, value := range remote {
+			assets = append(assets, theme.Asset{Key: key, Checksum: theme.Asset{Value: value}.Digest(), UpdatedAt: value})
+		}
+		return assets, nil
+	}
+	retrieve := func(key string) (theme.Asset, error) {
+		return theme.Asset{Key: key, Value: remote[key], UpdatedAt: remote[key]}, nil
+	}
+	return &remoteSync{
+		list:       listing,
+		retrieve:   retrieve,
+		ignore:     func(string) bool { return false },
+		root:       dir,
+		manifest:   themekit.NewManifest(""),
+		pending:    newPendingJobs(),
+		suppressed: newSuppressedWrites(),
+		reported:   map[string]string{},
+		eventLog:   make(chan themekit.ThemeEvent, 10),
+	}, dir
+}
+
+func synced(s *remoteSync, key, value string) {
+	ioutil.WriteFile(s.localPath(key), []byte(value), 0644)
+	s.manifest.Set(key, themekit.ManifestEntry{Checksum: theme.Asset{Value: value}.Digest(), UpdatedAt: value})
+}
+
+func TestPullingRemoteChanges(t *testing.T) {
+	s, dir := syncFixture(map[string]string{"snippets/changed.liquid": "remote", "snippets/new.liquid": "new"})
+	defer os.RemoveAll(dir)
+	synced(s, "snippets/changed.liquid", "synced")
+	synced(s, "snippets/removed.liquid", "synced")
+
+	s.poll()
+	for i := 0; i < 3; i++ {
+		assert.True(t, (<-s.eventLog).Successful())
+	}
+
+	contents, _ := ioutil.ReadFile(filepath.Join(dir, "snippets", "changed.liquid"))
+	assert.Equal(t, "remote", string(contents))
+	contents, _ = ioutil.ReadFile(filepath.Join(dir, "snippets", "new.liquid"))
+	assert.Equal(t, "new", string(contents))
+	_, err := os.Stat(filepath.Join(dir, "snippets", "removed.liquid"))
+	assert.True(t, os.IsNotExist(err))
+	entry, _ := s.manifest.Get("snippets/changed.liquid")
+	assert.Equal(t, "remote", entry.UpdatedAt)
+	_, found := s.manifest.Get("snippets/removed.liquid")
+	assert.False(t, found)
+
+	written, _ := theme.LoadAsset(dir, "snippets/changed.liquid")
+	assert.True(t, s.suppressed.matches(themekit.NewUploadEvent(written)), "Writes made by sync are not uploaded back")
+	assert.True(t, s.suppressed.matches(themekit.NewRemovalEvent(theme.Asset{Key: "snippets/removed.liquid"})))
+	assert.False(t, s.suppressed.matches(themekit.NewRemovalEvent(theme.Asset{Key: "snippets/removed.liquid"})), "Later local changes are uploaded")
+	assert.False(t, s.suppressed.matches(themekit.NewUploadEvent(written)))
+}
+
+func TestReportingConflictsForSavesDuringTheDownload(t *testing.T) {
+	s, dir := syncFixture(map[string]string{"snippets/both.liquid": "remote"})
+	defer os.RemoveAll(dir)
+	synced(s, "snippets/both.liquid", "synced")
+	retrieve := s.retrieve
+	s.retrieve = func(key string) (theme.Asset, error) {
+		ioutil.WriteFile(s.localPath(key), []byte("saved meanwhile"), 0644)
+		return retrieve(key)
+	}
+
+	s.poll()
+	conflict := (<-s.eventLog).(themekit.AssetConflictEvent)
+	assert.Equal(t, "snippets/both.liquid", conflict.AssetKey)
+	contents, _ := ioutil.ReadFile(s.localPath("snippets/both.liquid"))
+	assert.Equal(t, "saved meanwhile", string(contents))
+}
+
+func TestReportingConflictsWhenBothSidesChanged(t *testing.T) {
+	s, dir := syncFixture(map[string]string{"snippets/both.liquid": "remote"})
+	defer os.RemoveAll(dir)
+	synced(s, "snippets/both.liquid", "synced")
+	ioutil.WriteFile(s.localPath("snippets/both.liquid"), []byte("local"), 0644)
+
+	s.poll()
+	s.poll()
+	conflict := (<-s.eventLog).(themekit.AssetConflictEvent)
+	assert.Equal(t, "snippets/both.liquid", conflict.AssetKey)
+	assert.True(t, conflict.Local.Exists)
+	assert.Equal(t, 0, len(s.eventLog), "A conflict is reported once")
+
+	contents, _ := ioutil.ReadFile(s.localPath("snippets/both.liquid"))
+	assert.Equal(t, "local", string(contents))
+}
+
+func TestLeavingQueuedLocalChangesAlone(t *testing.T) {
+	s, dir := syncFixture(map[string]string{"snippets/queued.liquid": "remote"})
+	defer os.RemoveAll(dir)
+	synced(s, "snippets/queued.liquid", "synced")
+	jobs := make(chan themekit.AssetEvent)
+	queue := s.pending.track(jobs)
+	go func() {
+		jobs <- themekit.NewUploadEvent(theme.Asset{Key: "snippets/queued.liquid", Value: "synced"})
+	}()
+	<-queue
+
+	s.poll()
+	contents, _ := ioutil.ReadFile(s.localPath("snippets/queued.liquid"))
+	assert.Equal(t, "synced", string(contents))
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+)
+
+type themeAction func(args Args) error
+
+var themeActions = map[string]themeAction{
+	"list":      listThemes,
+	"create":    createTheme,
+	"publish":   publishTheme,
+	"rename":    renameTheme,
+	"duplicate": duplicateTheme,
+	"delete":    deleteTheme,
+}
+
+// ThemeActions returns the names of the actions the themes command can perform
+func ThemeActions() []string {
+	actions := []string{}
+	for action := range themeActions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// ThemesCommand lists, creates, publishes, renames, duplicates and deletes the themes on the shop
+func ThemesCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		name := "list"
+		if len(args.Filenames) > 0 {
+			name = args.Filenames[0]
+		}
+		action, found := themeActions[name]
+		if !found {
+			themekit.NotifyError(fmt.Errorf("'%s' is not a valid action, use one of: %s", name, strings.Join(ThemeActions(), ", ")))
+		} else if err := action(args); err != nil {
+			themekit.NotifyError(err)
+		}
+		done <- true
+	}()
+	return done
+}
+
+func listThemes(args Args) error {
+	themes, err := args.ThemeClient.Themes()
+	if err != nil {
+		return err
+	}
+	for _, t := range themes {
+		args.EventLog <- message(describeTheme(t))
+	}
+	return nil
+}
+
+func describeTheme(t theme.Theme) string {
+	role := fmt.Sprintf("%-13s", fmt.Sprintf("[%s]", t.Role))
+	if t.Role == "main" {
+		role = themekit.GreenText(role)
+	}
+	return fmt.Sprintf("%s %s %s", role, themekit.BlueText(fmt.Sprintf("%d", t.ID)), t.Name)
+}
+
+func createTheme(args Args) error {
+	if len(args.ThemeName) == 0 {
+		return fmt.Errorf("a name is required to create a theme")
+	}
+	return logThemeEvent(args.ThemeClient.CreateEmptyTheme(args.ThemeName), args.EventLog)
+}
+
+func publishTheme(args Args) error {
+	if args.ThemeID == 0 {
+		return fmt.Errorf("the id of the theme to publish is required")
+	}
+	return logThemeEvent(args.ThemeClient.PublishTheme(args.ThemeID), args.EventLog)
+}
+
+func renameTheme(args Args) error {
+	if args.ThemeID == 0 || len(args.ThemeName) == 0 {
+		return fmt.Errorf("the id of the theme and its new name are required")
+	}
+	return logThemeEvent(args.ThemeClient.RenameTheme(args.ThemeID, args.ThemeName), args.EventLog)
+}
+
+func deleteTheme(args Args) error {
+	if args.ThemeID == 0 {
+		return fmt.Errorf("the id of the theme to delete is required")
+	}
+	return logThemeEvent(args.ThemeClient.DeleteTheme(args.ThemeID), args.EventLog)
+}
+
+// duplicateTheme creates an empty theme and copies every asset of the source theme into it. The
+// copies go through the shop's leaky bucket, like every other bulk operation.
+func duplicateTheme(args Args) error {
+	if args.ThemeID == 0 {
+		return fmt.Errorf("the id of the theme to duplicate is required")
+	}
+	client := args.ThemeClient
+	name := args.ThemeName
+	if len(name) == 0 {
+		name = fmt.Sprintf("Copy of theme %d", args.ThemeID)
+	}
+
+	source := client.ForTheme(args.ThemeID)
+	assets, err := listAssets(source)
+	if err != nil {
+		return err
+	}
+
+	created := client.CreateEmptyTheme(name)
+	if err := logThemeEvent(created, args.EventLog); err != nil {
+		return err
+	}
+	target := client.ForTheme(created.ThemeID)
+
+	bucket := client.LeakyBucket()
+	bucket.TopUp()
+	bucket.StartDripping()
+	defer bucket.StopDripping()
+	foreman := themekit.NewForeman(bucket)
+	foreman.IssueWork()
+	go func() {
+		for _, asset := range assets {
+			foreman.JobQueue <- themekit.NewUploadEvent(asset)
+		}
+		close(foreman.JobQueue)
+	}()
+
+	copied, failed := 0, 0
+	for job := range foreman.WorkerQueue {
+		loaded, err := source.LoadContents(job.Asset())
+		if err != nil {
+			args.EventLog <- downloadErrorEvent("", job.Asset().Key, err)
+			failed++
+			continue
+		}
+		bucket.GetDrop()
+		event := target.Perform(themekit.NewUploadEvent(loaded))
+		args.EventLog <- event
+		if _, noOp := event.(themekit.NoOpEvent); noOp {
+			continue
+		}
+		if event.Successful() {
+			copied++
+		} else {
+			failed++
+		}
+	}
+	args.EventLog <- message(fmt.Sprintf("Copied %d of %d file(s) into theme %d, %d failed", copied, copied+failed, created.ThemeID, failed))
+	return nil
+}
+
+func logThemeEvent(event themekit.APIThemeEvent, eventLog chan themekit.ThemeEvent) error {
+	if !event.Successful() {
+		return event.Error()
+	}
+	eventLog <- event
+	return nil
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemeActions(t *testing.T) {
+	assert.Equal(t, []string{"create", "delete", "duplicate", "list", "publish", "rename"}, ThemeActions())
+}
+
+func TestDescribingATheme(t *testing.T) {
+	description := describeTheme(theme.Theme{ID: 123, Name: "Debut", Role: "unpublished"})
+	assert.True(t, strings.Contains(description, "[unpublished]"))
+	assert.True(t, strings.Contains(description, "123"))
+	assert.True(t, strings.HasSuffix(description, "Debut"))
+}
+
+func TestDuplicatingListsTheSourceBeforeCreatingTheCopy(t *testing.T) {
+	created := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			created++
+		}
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	defer trustServer(ts)()
+
+	args := DefaultArgs()
+	args.ThemeID = 1
+	args.ThemeClient = themekit.NewThemeClient(themekit.Configuration{Domain: ts.Listener.Addr().String(), AccessToken: "abra", MaxAttempts: 1})
+	args.EventLog = make(chan themekit.ThemeEvent)
+
+	err := duplicateTheme(args)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, created, "No empty theme is left behind when the source cannot be listed")
+}
+
+func TestDuplicatingATheme(t *testing.T) {
+	uploaded := []string{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			w.Write([]byte(`{"theme":{"id":2,"name":"Copy","role":"unpublished"}}`))
+		case r.Method == "PUT":
+			uploaded = append(uploaded, r.URL.Path)
+			w.Write([]byte(`{"asset":{"key":"layout/theme.liquid"}}`))
+		case len(r.URL.Query().Get("asset[key]")) > 0:
+			w.Write([]byte(`{"asset":{"key":"` + r.URL.Query().Get("asset[key]") + `","value":"contents"}}`))
+		default:
+			w.Write([]byte(`{"assets":[{"key":"layout/theme.liquid"},{"key":"templates/index.liquid"}]}`))
+		}
+	}))
+	defer ts.Close()
+	defer trustServer(ts)()
+
+	args := DefaultArgs()
+	args.ThemeID = 1
+	args.ThemeClient = themekit.NewThemeClient(themekit.Configuration{Domain: ts.Listener.Addr().String(), AccessToken: "abra", BucketSize: 4, RefillRate: 4, MaxAttempts: 1})
+	args.EventLog = make(chan themekit.ThemeEvent)
+	go func() {
+		for range args.EventLog {
+		}
+	}()
+
+	assert.Nil(t, duplicateTheme(args))
+	assert.Equal(t, []string{"/admin/themes/2/assets.json", "/admin/themes/2/assets.json"}, uploaded)
+}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"encoding/json"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -48,7 +47,7 @@ func UpdateCommand(args Args) chan bool {
 	latestRelease, err := downloadReleaseForPlatform()
 	if err == nil {
 		if latestRelease.IsApplicable() {
-			fmt.Println("Updating from", themekit.TKVersion, "to", Version(latestRelease))
+			themekit.Notice("Updating from", themekit.TKVersion, "to", Version(latestRelease))
 			releaseForPlatform := findAppropriateRelease(latestRelease)
 			themekit.ApplyUpdate(releaseForPlatform.URL, releaseForPlatform.Digest)
 		}
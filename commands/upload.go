@@ -7,10 +7,26 @@ import (
 
 // UploadCommand add file(s) to theme
 func UploadCommand(args Args) chan bool {
+	if args.DryRun {
+		return dryRun(args, func(remoteAssets []theme.Asset) []themekit.AssetEvent {
+			return uploadEvents(args)
+		})
+	}
+
+	manifest := loadManifest(args)
 	files := make(chan themekit.AssetEvent)
 	go ReadAndPrepareFiles(args, files)
+	root, _ := args.WorkingDirGetter()
+	files = guardJSON(root, files, args.EventLog)
+	if args.Lint {
+		files = guardLint(files, args.EventLog)
+	}
+	if !args.Force {
+		files = guardConflicts(remoteListingLookup(args.ThemeClient), manifest, files, args.EventLog)
+	}
 
 	done, events := args.ThemeClient.Process(files)
+	done, events = recordSyncs(manifest, done, events)
 	mergeEvents(args.EventLog, []chan themekit.ThemeEvent{events})
 	return done
 }
@@ -29,6 +45,22 @@ func ReadAndPrepareFiles(args Args, results chan themekit.AssetEvent) {
 	close(results)
 }
 
+func uploadEvents(args Args) []themekit.AssetEvent {
+	files := make(chan themekit.AssetEvent)
+	go ReadAndPrepareFiles(args, files)
+	root, _ := args.WorkingDirGetter()
+	files = guardJSON(root, files, args.EventLog)
+	if args.Lint {
+		files = guardLint(files, args.EventLog)
+	}
+
+	events := []themekit.AssetEvent{}
+	for event := range files {
+		events = append(events, event)
+	}
+	return events
+}
+
 func loadAsset(args Args, filename string) (asset theme.Asset, err error) {
 	root, err := args.WorkingDirGetter()
 	if err != nil {
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+)
+
+// InvalidThemeExitCode is the exit status used when validation finds problems
+const InvalidThemeExitCode = 3
+
+type validationProblem struct {
+	AssetKey string `json:"asset_key"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+	Etype    string `json:"type"`
+}
+
+func newValidationProblem(problem theme.Problem) validationProblem {
+	return validationProblem{
+		AssetKey: problem.Key,
+		Line:     problem.Line,
+		Column:   problem.Column,
+		Message:  problem.Message,
+		Etype:    "validationProblem",
+	}
+}
+
+func (v validationProblem) String() string {
+	target := v.AssetKey
+	if v.Line > 0 {
+		target = fmt.Sprintf("%s:%d:%d", target, v.Line, v.Column)
+	}
+	return fmt.Sprintf("[%s] %s %s", themekit.RedText("invalid"), themekit.BlueText(target), v.Message)
+}
+
+func (v validationProblem) Successful() bool {
+	return false
+}
+
+func (v validationProblem) Error() error {
+	return theme.Problem{Key: v.AssetKey, Line: v.Line, Column: v.Column, Message: v.Message}
+}
+
+func (v validationProblem) AsJSON() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// ValidateCommand checks the local theme, or the named files, for problems Shopify would
+// reject them for without making any requests. Locales that do not match the default locale
+// are only warned about, as upload does.
+func ValidateCommand(args Args) chan bool {
+	done := make(chan bool)
+	go func() {
+		root, err := args.WorkingDirGetter()
+		if err != nil {
+			themekit.NotifyError(err)
+		}
+
+		var problems, warnings []theme.Problem
+		if len(args.Filenames) == 0 {
+			assets := args.ThemeClient.LocalAssets(root)
+			problems = theme.Validate(assets)
+			warnings = theme.ValidateLocales(assets)
+		} else {
+			for _, asset := range loadNamedAssets(root, args.Filenames) {
+				problems = append(problems, theme.ValidateAsset(asset)...)
+				warnings = append(warnings, compareWithDefaultLocale(root, asset)...)
+			}
+		}
+
+		for _, problem := range problems {
+			args.EventLog <- newValidationProblem(problem)
+		}
+		for _, warning := range warnings {
+			args.EventLog <- localeWarning(warning)
+		}
+		if len(problems) > 0 {
+			args.EventLog <- message(themekit.RedText(fmt.Sprintf("Found %d problem(s)", len(problems))))
+		} else {
+			args.EventLog <- message(themekit.GreenText("No problems found"))
+		}
+		done <- true
+	}()
+	return done
+}
+
+// reportJSONProblems logs the problems with an uploaded JSON asset and reports whether it would
+// be rejected. Locales that do not match the default locale are reported but still uploaded,
+// since Shopify falls back to the default locale for missing translations.
+func reportJSONProblems(root string, event themekit.AssetEvent, eventLog chan themekit.ThemeEvent) bool {
+	if event.Type() != themekit.Update {
+		return false
+	}
+	asset := event.Asset()
+	problems := theme.ValidateJSON(asset)
+	for _, problem := range problems {
+		logEvent(newValidationProblem(problem), eventLog)
+	}
+	if len(problems) > 0 {
+		return true
+	}
+	for _, problem := range compareWithDefaultLocale(root, asset) {
+		logEvent(localeWarning(problem), eventLog)
+	}
+	return false
+}
+
+// localeWarning reports a locale that does not match the default locale. It is not a failure,
+// since Shopify falls back to the default locale for missing translations.
+func localeWarning(problem theme.Problem) themekit.ThemeEvent {
+	return message(themekit.YellowText(problem.Error()))
+}
+
+func compareWithDefaultLocale(root string, locale theme.Asset) []theme.Problem {
+	if !strings.HasPrefix(locale.Key, "locales/") || strings.HasSuffix(locale.Key, ".default.json") {
+		return nil
+	}
+	matches, err := filepath.Glob(filepath.Join(root, "locales", "*.default.json"))
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+	defaultKey := "locales/" + filepath.Base(matches[0])
+	defaultLocale, err := theme.LoadAsset(root, defaultKey)
+	if err != nil {
+		return nil
+	}
+	expected, ok := theme.LocaleKeys(defaultLocale)
+	if !ok {
+		return nil
+	}
+	return theme.CompareLocale(locale, expected, defaultKey)
+}
+
+// guardJSON holds back uploads of JSON assets that cannot be parsed
+func guardJSON(root string, events chan themekit.AssetEvent, eventLog chan themekit.ThemeEvent) chan themekit.AssetEvent {
+	return holdBack(events, func(event themekit.AssetEvent) bool {
+		return reportJSONProblems(root, event, eventLog)
+	})
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/themekit"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatingNamedFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "validate")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "snippets"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "snippets", "header.liquid"), []byte("Header"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "snippets", "footer.html"), []byte("Footer"), 0644)
+
+	args := DefaultArgs()
+	args.ThemeClient = themekit.NewThemeClient(themekit.Configuration{})
+	args.WorkingDirGetter = func() (string, error) { return dir, nil }
+	args.Filenames = []string{"snippets/header.liquid", "snippets/footer.html"}
+	args.EventLog = make(chan themekit.ThemeEvent)
+
+	done := ValidateCommand(args)
+	events := []themekit.ThemeEvent{}
+	outcomes := NewOutcomes()
+	for finished := false; !finished; {
+		select {
+		case event := <-args.EventLog:
+			events = append(events, event)
+			outcomes.Record(event)
+		case <-done:
+			finished = true
+		}
+	}
+
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "snippets/footer.html", events[0].(validationProblem).AssetKey)
+	assert.Equal(t, InvalidThemeExitCode, outcomes.ExitCode())
+}
+
+func TestGuardingUploadsOfInvalidJSON(t *testing.T) {
+	events := make(chan themekit.AssetEvent)
+	eventLog := make(chan themekit.ThemeEvent)
+	passed := guardJSON("", events, eventLog)
+
+	go func() {
+		events <- themekit.NewUploadEvent(theme.Asset{Key: "config/settings_data.json", Value: "{\"current\": }"})
+		events <- themekit.NewUploadEvent(theme.Asset{Key: "locales/en.default.json", Value: "{}"})
+		close(events)
+	}()
+
+	problem := (<-eventLog).(validationProblem)
+	assert.Equal(t, "config/settings_data.json", problem.AssetKey)
+	assert.Equal(t, 1, problem.Line)
+
+	uploaded := []string{}
+	for event := range passed {
+		uploaded = append(uploaded, event.Asset().Key)
+	}
+	assert.Equal(t, []string{"locales/en.default.json"}, uploaded)
+}
+
+func TestOnlyWarningAboutLocaleMismatches(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "validate")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "locales"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "locales", "en.default.json"), []byte(`{"home": "Home", "cart": "Cart"}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "locales", "fr.json"), []byte(`{"home": "Accueil"}`), 0644)
+
+	args := DefaultArgs()
+	args.ThemeClient = themekit.NewThemeClient(themekit.Configuration{})
+	args.WorkingDirGetter = func() (string, error) { return dir, nil }
+	args.Filenames = []string{"locales/fr.json"}
+	args.EventLog = make(chan themekit.ThemeEvent)
+
+	done := ValidateCommand(args)
+	events := []themekit.ThemeEvent{}
+	outcomes := NewOutcomes()
+	for finished := false; !finished; {
+		select {
+		case event := <-args.EventLog:
+			events = append(events, event)
+			outcomes.Record(event)
+		case <-done:
+			finished = true
+		}
+	}
+
+	assert.Equal(t, 2, len(events), "The missing translation is warned about")
+	assert.Equal(t, 0, outcomes.ExitCode(), "Upload accepts locales that do not match the default one")
+}
@@ -3,12 +3,16 @@ package commands
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/Shopify/themekit"
 )
 
-// WatchCommand watches directories for changes, and updates the remote theme
+// WatchCommand watches directories for changes, and updates the remote theme. On SIGINT or
+// SIGTERM it stops watching and waits for the queued changes to be uploaded before finishing.
 func WatchCommand(args Args) chan bool {
 	if isSingleEnvironment(args) {
 		args.ThemeClients = []themekit.ThemeClient{args.ThemeClient}
@@ -16,6 +20,9 @@ func WatchCommand(args Args) chan bool {
 
 	done := make(chan bool)
 	eventLog := args.EventLog
+	stop := make(chan bool)
+	pending := newPendingJobs()
+	var workers sync.WaitGroup
 
 	for _, client := range args.ThemeClients {
 		config := client.GetConfiguration()
@@ -23,9 +30,17 @@ func WatchCommand(args Args) chan bool {
 		logEvent(message(fmt.Sprintf("Spawning %d workers for %s", concurrency, config.Domain)), eventLog)
 
 		args.ThemeClient = client
-		watchForChangesAndIssueWork(args, eventLog)
+		watchForChangesAndIssueWork(args, stop, pending, &workers, eventLog)
 	}
 
+	interrupted := make(chan os.Signal, 2)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		drain(interrupted, stop, &workers, pending, DrainTimeout, eventLog)
+		signal.Stop(interrupted)
+		done <- true
+	}()
+
 	return done
 }
 
@@ -33,7 +48,7 @@ func isSingleEnvironment(args Args) bool {
 	return len(args.ThemeClients) == 0
 }
 
-func watchForChangesAndIssueWork(args Args, eventLog chan themekit.ThemeEvent) {
+func watchForChangesAndIssueWork(args Args, stop chan bool, pending *pendingJobs, workers *sync.WaitGroup, eventLog chan themekit.ThemeEvent) {
 	client := args.ThemeClient
 	config := client.GetConfiguration()
 	bucket := client.LeakyBucket()
@@ -46,49 +61,97 @@ func watchForChangesAndIssueWork(args Args, eventLog chan themekit.ThemeEvent) {
 			os.Chtimes(args.NotifyFile, time.Now(), time.Now())
 		}
 	}
-	watcher := constructFileWatcher(args.Directory, config)
-	foreman.JobQueue = watcher
+	manifest := loadManifest(args)
+	watcher := constructFileWatcher(args.Directory, args.PollInterval, config, stop, eventLog)
+	events := themekit.CoalesceEvents(watcher, time.Duration(config.QuietPeriod)*time.Millisecond)
+	if args.Sync {
+		suppressed := newSuppressedWrites()
+		events = holdBack(events, suppressed.matches)
+		go newRemoteSync(client, args, manifest, pending, suppressed).run(args.SyncInterval, stop)
+	}
+	foreman.JobQueue = pending.track(events)
 	foreman.IssueWork()
 
 	for i := 0; i < config.Concurrency; i++ {
 		workerName := fmt.Sprintf("%s Worker #%d", config.Domain, i)
-		go spawnWorker(workerName, foreman.WorkerQueue, client, eventLog)
+		workers.Add(1)
+		go func() {
+			spawnWorker(workerName, foreman.WorkerQueue, client, manifest, args, pending, eventLog)
+			workers.Done()
+		}()
 	}
 }
 
-func spawnWorker(workerName string, queue chan themekit.AssetEvent, client themekit.ThemeClient, eventLog chan themekit.ThemeEvent) {
+// spawnWorker performs the events of the queue until it is closed
+func spawnWorker(workerName string, queue chan themekit.AssetEvent, client themekit.ThemeClient, manifest *themekit.Manifest, args Args, pending *pendingJobs, eventLog chan themekit.ThemeEvent) {
+	lookup := remoteAssetLookup(client)
 	logEvent(workerSpawnEvent(workerName), eventLog)
-	for {
-		asset := <-queue
-		if asset.Asset().IsValid() {
-			workerEvent := basicEvent{
-				Title:     "FS Event",
-				EventType: asset.Type().String(),
-				Target:    asset.Asset().Key,
-				Etype:     "fsevent",
-				Formatter: func(b basicEvent) string {
-					return fmt.Sprintf(
-						"Received %s event on %s",
-						themekit.GreenText(b.EventType),
-						themekit.BlueText(b.Target),
-					)
-				},
-			}
-			logEvent(workerEvent, eventLog)
-			logEvent(client.Perform(asset), eventLog)
+	for asset := range queue {
+		if asset.Type() == themekit.Remove || asset.Asset().IsValid() {
+			performWatchedEvent(asset, client, lookup, manifest, args, eventLog)
+		}
+		pending.done(asset)
+	}
+}
+
+func performWatchedEvent(asset themekit.AssetEvent, client themekit.ThemeClient, lookup remoteLookup, manifest *themekit.Manifest, args Args, eventLog chan themekit.ThemeEvent) {
+	workerEvent := basicEvent{
+		Title:     "FS Event",
+		EventType: asset.Type().String(),
+		Target:    asset.Asset().Key,
+		Etype:     "fsevent",
+		Formatter: func(b basicEvent) string {
+			return fmt.Sprintf(
+				"Received %s event on %s",
+				themekit.GreenText(b.EventType),
+				themekit.BlueText(b.Target),
+			)
+		},
+	}
+	logEvent(workerEvent, eventLog)
+	if reportJSONProblems(args.Directory, asset, eventLog) {
+		return
+	}
+	if args.Lint && reportLintProblems(asset, eventLog) {
+		return
+	}
+	if !args.Force {
+		if conflict, found := checkConflict(lookup, manifest, asset); found {
+			logEvent(conflict, eventLog)
+			return
 		}
 	}
+	event := client.Perform(asset)
+	manifest.Record(event)
+	saveManifest(manifest)
+	logEvent(event, eventLog)
 }
 
-func constructFileWatcher(dir string, config themekit.Configuration) chan themekit.AssetEvent {
+func constructFileWatcher(dir string, pollInterval time.Duration, config themekit.Configuration, stop chan bool, eventLog chan themekit.ThemeEvent) chan themekit.AssetEvent {
 	filter := themekit.NewEventFilterFromPatternsAndFiles(config.IgnoredFiles, config.Ignores)
-	watcher, err := themekit.NewFileWatcher(dir, true, filter)
+	if pollInterval > 0 {
+		watcher, errs, err := themekit.NewPollingWatcher(dir, pollInterval, filter, stop)
+		if err != nil {
+			themekit.NotifyError(err)
+		}
+		go reportWatchErrors(errs, eventLog)
+		return watcher
+	}
+	watcher, errs, err := themekit.NewFileWatcher(dir, true, filter, stop)
 	if err != nil {
 		themekit.NotifyError(err)
 	}
+	go reportWatchErrors(errs, eventLog)
 	return watcher
 }
 
+// reportWatchErrors logs the problems met while watching, which do not stop watch
+func reportWatchErrors(errs chan error, eventLog chan themekit.ThemeEvent) {
+	for err := range errs {
+		logEvent(message(themekit.RedText(fmt.Sprintf("Problem while watching for changes: %s", err))), eventLog)
+	}
+}
+
 func workerSpawnEvent(workerName string) themekit.ThemeEvent {
 	return basicEvent{
 		Title:     "Worker",
@@ -13,17 +13,20 @@ import (
 
 // Configuration ... TODO
 type Configuration struct {
-	AccessToken  string   `yaml:"access_token,omitempty"`
-	Password     string   `yaml:"password,omitempty"`
-	ThemeID      int64    `yaml:"theme_id,omitempty"`
-	Domain       string   `yaml:"store"`
-	URL          string   `yaml:"-"`
-	IgnoredFiles []string `yaml:"ignore_files,omitempty"`
-	BucketSize   int      `yaml:"bucket_size"`
-	RefillRate   int      `yaml:"refill_rate"`
-	Concurrency  int      `yaml:"concurrency,omitempty"`
-	Proxy        string   `yaml:"proxy,omitempty"`
-	Ignores      []string `yaml:"ignores,omitempty"`
+	AccessToken   string   `yaml:"access_token,omitempty"`
+	Password      string   `yaml:"password,omitempty"`
+	ThemeID       int64    `yaml:"theme_id,omitempty"`
+	Domain        string   `yaml:"store"`
+	URL           string   `yaml:"-"`
+	IgnoredFiles  []string `yaml:"ignore_files,omitempty"`
+	BucketSize    int      `yaml:"bucket_size"`
+	RefillRate    int      `yaml:"refill_rate"`
+	Concurrency   int      `yaml:"concurrency,omitempty"`
+	Proxy         string   `yaml:"proxy,omitempty"`
+	Ignores       []string `yaml:"ignores,omitempty"`
+	MaxAttempts   int      `yaml:"max_attempts,omitempty"`
+	MaxRetryDelay int      `yaml:"max_retry_delay,omitempty"`
+	QuietPeriod   int      `yaml:"quiet_period,omitempty"`
 }
 
 const (
@@ -33,6 +36,12 @@ const (
 	DefaultRefillRate int = 2
 	// DefaultConcurrency ... TODO
 	DefaultConcurrency int = 2
+	// DefaultMaxAttempts is how many times a throttled or failed request is attempted
+	DefaultMaxAttempts int = 5
+	// DefaultMaxRetryDelay is the longest wait, in seconds, before a request is attempted again
+	DefaultMaxRetryDelay int = 30
+	// DefaultQuietPeriod is how long, in milliseconds, watch waits for changes to settle before uploading them
+	DefaultQuietPeriod int = 300
 )
 
 // LoadConfiguration ... TODO
@@ -55,6 +64,15 @@ func (conf Configuration) Initialize() (Configuration, error) {
 	if conf.Concurrency <= 0 {
 		conf.Concurrency = DefaultConcurrency
 	}
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = DefaultMaxAttempts
+	}
+	if conf.MaxRetryDelay <= 0 {
+		conf.MaxRetryDelay = DefaultMaxRetryDelay
+	}
+	if conf.QuietPeriod <= 0 {
+		conf.QuietPeriod = DefaultQuietPeriod
+	}
 
 	conf.URL = conf.AdminURL()
 	if conf.ThemeID != 0 {
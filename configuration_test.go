@@ -18,6 +18,9 @@ func TestLoadingAValidConfiguration(t *testing.T) {
 	assert.Equal(t, "https://example.myshopify.com/admin", config.URL)
 	assert.Equal(t, "https://example.myshopify.com/admin/assets.json", config.AssetPath())
 	assert.Equal(t, 4, config.Concurrency)
+	assert.Equal(t, DefaultMaxAttempts, config.MaxAttempts)
+	assert.Equal(t, DefaultMaxRetryDelay, config.MaxRetryDelay)
+	assert.Equal(t, DefaultQuietPeriod, config.QuietPeriod)
 	assert.Nil(t, config.IgnoredFiles)
 }
 
@@ -0,0 +1,96 @@
+package themekit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Shopify/themekit/theme"
+)
+
+// AssetVersion describes one side of a conflicting asset
+type AssetVersion struct {
+	Checksum  string `json:"checksum,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	Size      int    `json:"size"`
+	Exists    bool   `json:"exists"`
+}
+
+// AssetConflictEvent is reported instead of performing an operation that would
+// overwrite a remote change made since the asset was last synced.
+type AssetConflictEvent struct {
+	AssetKey  string        `json:"asset_key"`
+	EventType string        `json:"event_type"`
+	Local     AssetVersion  `json:"local"`
+	Remote    AssetVersion  `json:"remote"`
+	Synced    ManifestEntry `json:"synced"`
+	Etype     string        `json:"type"`
+}
+
+// FindConflict checks whether performing event would overwrite a remote change
+// that has not been synced locally. Assets that were never synced do not conflict.
+func FindConflict(event AssetEvent, remote theme.Asset, remoteExists bool, manifest *Manifest) (AssetConflictEvent, bool) {
+	local := event.Asset()
+	synced, found := manifest.Get(local.Key)
+	if !found || (!remoteExists && event.Type() == Remove) {
+		return AssetConflictEvent{}, false
+	}
+	if remoteExists && !remoteChangedSince(remote, synced) {
+		return AssetConflictEvent{}, false
+	}
+	if remoteExists && event.Type() == Update && remote.Checksum == local.Digest() {
+		return AssetConflictEvent{}, false
+	}
+
+	conflict := AssetConflictEvent{
+		AssetKey:  local.Key,
+		EventType: event.Type().String(),
+		Remote:    AssetVersion{Checksum: remote.Checksum, UpdatedAt: remote.UpdatedAt, Size: remote.Size(), Exists: remoteExists},
+		Synced:    synced,
+		Etype:     "AssetConflictEvent",
+	}
+	if event.Type() == Update {
+		conflict.Local = AssetVersion{Checksum: local.Digest(), Size: local.Size(), Exists: true}
+	}
+	return conflict, true
+}
+
+// Matches reports whether a remote asset is still as it was when the entry was recorded
+func (e ManifestEntry) Matches(remote theme.Asset) bool {
+	return !remoteChangedSince(remote, e)
+}
+
+func remoteChangedSince(remote theme.Asset, synced ManifestEntry) bool {
+	if len(remote.Checksum) > 0 && len(synced.Checksum) > 0 {
+		return remote.Checksum != synced.Checksum
+	}
+	return len(remote.UpdatedAt) > 0 && remote.UpdatedAt != synced.UpdatedAt
+}
+
+func (c AssetConflictEvent) String() string {
+	remote := "was removed from Shopify"
+	if c.Remote.Exists {
+		remote = fmt.Sprintf("was changed on Shopify at %s", YellowText(c.Remote.UpdatedAt))
+	}
+	return fmt.Sprintf(
+		"[%s]Refusing to %s %s: it %s since it was last synced (%s). Use --force to overwrite it",
+		RedText("conflict"),
+		YellowText(c.EventType),
+		BlueText(c.AssetKey),
+		remote,
+		YellowText(c.Synced.UpdatedAt),
+	)
+}
+
+// Successful is always false since the operation was not performed
+func (c AssetConflictEvent) Successful() bool {
+	return false
+}
+
+func (c AssetConflictEvent) Error() error {
+	return fmt.Errorf("%s changed remotely since it was last synced", c.AssetKey)
+}
+
+// AsJSON encodes the conflict along with the metadata of both versions
+func (c AssetConflictEvent) AsJSON() ([]byte, error) {
+	return json.Marshal(c)
+}
@@ -0,0 +1,45 @@
+package themekit
+
+import (
+	"testing"
+
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindConflict(t *testing.T) {
+	manifest := NewManifest("")
+	manifest.Set("templates/index.liquid", ManifestEntry{Checksum: "synced", UpdatedAt: "2016-06-01T10:00:00-04:00"})
+
+	local := theme.Asset{Key: "templates/index.liquid", Value: "local edit"}
+	unchanged := theme.Asset{Key: "templates/index.liquid", Checksum: "synced", UpdatedAt: "2016-06-01T10:00:00-04:00"}
+	edited := theme.Asset{Key: "templates/index.liquid", Checksum: "edited", UpdatedAt: "2016-06-02T10:00:00-04:00"}
+	sameAsLocal := theme.Asset{Key: "templates/index.liquid", Checksum: local.Digest(), UpdatedAt: "2016-06-02T10:00:00-04:00"}
+
+	tests := []struct {
+		event        AssetEvent
+		remote       theme.Asset
+		remoteExists bool
+		conflicts    bool
+		desc         string
+	}{
+		{NewUploadEvent(local), unchanged, true, false, "remote has not changed since the last sync"},
+		{NewUploadEvent(local), edited, true, true, "remote was edited since the last sync"},
+		{NewUploadEvent(local), sameAsLocal, true, false, "remote already matches the local file"},
+		{NewUploadEvent(local), theme.Asset{}, false, true, "remote was removed since the last sync"},
+		{NewRemovalEvent(local), edited, true, true, "removing an asset that was edited remotely"},
+		{NewRemovalEvent(local), theme.Asset{}, false, false, "removing an asset that is already gone"},
+		{NewUploadEvent(theme.Asset{Key: "snippets/never-synced.liquid"}), edited, true, false, "asset was never synced"},
+	}
+
+	for _, test := range tests {
+		conflict, found := FindConflict(test.event, test.remote, test.remoteExists, manifest)
+		assert.Equal(t, test.conflicts, found, test.desc)
+		if found {
+			assert.Equal(t, test.event.Asset().Key, conflict.AssetKey, test.desc)
+			assert.Equal(t, "synced", conflict.Synced.Checksum, test.desc)
+			assert.Equal(t, test.remote.Checksum, conflict.Remote.Checksum, test.desc)
+			assert.False(t, conflict.Successful())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package diff compares and merges text assets line by line.
+package diff
+
+import "strings"
+
+// Lines splits text into lines, keeping the line terminators so that joining
+// the lines gives back the original text.
+func Lines(text string) []string {
+	lines := []string{}
+	for len(text) > 0 {
+		end := strings.Index(text, "\n")
+		if end < 0 {
+			end = len(text) - 1
+		}
+		lines = append(lines, text[:end+1])
+		text = text[end+1:]
+	}
+	return lines
+}
+
+// match finds a longest common subsequence of a and b. The result holds, for
+// every line of a, the index of the line of b it was matched with or -1.
+func match(a, b []string) []int {
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(middleA), len(middleB)
+	if n == 0 || m == 0 {
+		return matches
+	}
+
+	// lengths[i][j] is the length of the LCS of middleA[i:] and middleB[j:]
+	lengths := make([][]int32, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if middleA[i] == middleB[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case middleA[i] == middleB[j]:
+			matches[prefix+i] = prefix + j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
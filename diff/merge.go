@@ -0,0 +1,74 @@
+package diff
+
+import "strings"
+
+const (
+	localMarker  = "<<<<<<< local\n"
+	separator    = "=======\n"
+	remoteMarker = ">>>>>>> remote\n"
+)
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	Lines     []string
+	Conflicts int
+}
+
+func (r MergeResult) String() string {
+	return strings.Join(r.Lines, "")
+}
+
+// Merge3 merges the changes made to base in local and in remote. Regions that
+// changed differently on both sides are kept with conflict markers around them.
+func Merge3(base, local, remote string) MergeResult {
+	baseLines, localLines, remoteLines := Lines(base), Lines(local), Lines(remote)
+	toLocal, toRemote := match(baseLines, localLines), match(baseLines, remoteLines)
+
+	result := MergeResult{Lines: []string{}}
+	b, l, r := 0, 0, 0
+	for b < len(baseLines) || l < len(localLines) || r < len(remoteLines) {
+		if b < len(baseLines) && toLocal[b] == l && toRemote[b] == r {
+			result.Lines = append(result.Lines, baseLines[b])
+			b, l, r = b+1, l+1, r+1
+			continue
+		}
+
+		// find the next base line kept by both sides, everything before it is a changed chunk
+		next, nextLocal, nextRemote := b, len(localLines), len(remoteLines)
+		for ; next < len(baseLines); next++ {
+			if toLocal[next] >= 0 && toRemote[next] >= 0 {
+				nextLocal, nextRemote = toLocal[next], toRemote[next]
+				break
+			}
+		}
+		result.merge(baseLines[b:next], localLines[l:nextLocal], remoteLines[r:nextRemote])
+		b, l, r = next, nextLocal, nextRemote
+	}
+	return result
+}
+
+func (r *MergeResult) merge(base, local, remote []string) {
+	switch {
+	case equalLines(local, base) || equalLines(local, remote):
+		r.Lines = append(r.Lines, remote...)
+	case equalLines(remote, base):
+		r.Lines = append(r.Lines, local...)
+	default:
+		r.Conflicts++
+		r.Lines = append(r.Lines, localMarker)
+		r.Lines = append(r.Lines, terminated(local)...)
+		r.Lines = append(r.Lines, separator)
+		r.Lines = append(r.Lines, terminated(remote)...)
+		r.Lines = append(r.Lines, remoteMarker)
+	}
+}
+
+// terminated makes sure the last line ends with a newline so a conflict marker can follow it
+func terminated(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+	result := append([]string{}, lines...)
+	result[len(result)-1] += "\n"
+	return result
+}
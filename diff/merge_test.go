@@ -0,0 +1,37 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinesKeepsTerminators(t *testing.T) {
+	assert.Equal(t, []string{"a\n", "b\n", "c"}, Lines("a\nb\nc"))
+	assert.Equal(t, []string{}, Lines(""))
+}
+
+func TestMerge3(t *testing.T) {
+	base := "one\ntwo\nthree\nfour\n"
+	tests := []struct {
+		local, remote, expected string
+		conflicts               int
+		desc                    string
+	}{
+		{base, base, base, 0, "nothing changed"},
+		{"one\n2\nthree\nfour\n", base, "one\n2\nthree\nfour\n", 0, "only local changed"},
+		{base, "one\ntwo\nthree\n4\n", "one\ntwo\nthree\n4\n", 0, "only remote changed"},
+		{"one\n2\nthree\nfour\n", "one\ntwo\nthree\n4\n", "one\n2\nthree\n4\n", 0, "both changed different lines"},
+		{"zero\none\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\nfive\n", "zero\none\ntwo\nthree\nfour\nfive\n", 0, "both added lines at different ends"},
+		{"one\n2\nthree\nfour\n", "one\n2\nthree\nfour\n", "one\n2\nthree\nfour\n", 0, "both made the same change"},
+		{"one\nlocal\nthree\nfour\n", "one\nremote\nthree\nfour\n", "one\n<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> remote\nthree\nfour\n", 1, "both changed the same line"},
+		{"one\ntwo\nthree\nlocal", "one\ntwo\nthree\nremote", "one\ntwo\nthree\n<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> remote\n", 1, "conflicting last lines without newline"},
+		{"one\nthree\nfour\n", "one\ntwo\nthree\n4\n", "one\nthree\n4\n", 0, "local removed a line remote did not touch"},
+	}
+
+	for _, test := range tests {
+		result := Merge3(base, test.local, test.remote)
+		assert.Equal(t, test.expected, result.String(), test.desc)
+		assert.Equal(t, test.conflicts, result.Conflicts, test.desc)
+	}
+}
@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+type edit struct {
+	op   byte
+	line string
+	// number of lines of a and b before this edit
+	a, b int
+}
+
+func edits(a, b []string) []edit {
+	matches := match(a, b)
+	script := []edit{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && matches[i] == j:
+			script = append(script, edit{op: ' ', line: a[i], a: i, b: j})
+			i++
+			j++
+		case i < len(a) && matches[i] < 0:
+			script = append(script, edit{op: '-', line: a[i], a: i, b: j})
+			i++
+		default:
+			script = append(script, edit{op: '+', line: b[j], a: i, b: j})
+			j++
+		}
+	}
+	return script
+}
+
+// Unified returns a unified diff that turns a into b, or an empty string if they are the same
+func Unified(fromName, toName, a, b string, context int) string {
+	script := edits(Lines(a), Lines(b))
+	changes := []int{}
+	for index, e := range script {
+		if e.op != ' ' {
+			changes = append(changes, index)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	buffer := bytes.NewBufferString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+	for first := 0; first < len(changes); {
+		last := first
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context {
+			last++
+		}
+		start, end := changes[first]-context, changes[last]+context+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(script) {
+			end = len(script)
+		}
+		writeHunk(buffer, script[start:end])
+		first = last + 1
+	}
+	return buffer.String()
+}
+
+func writeHunk(buffer *bytes.Buffer, hunk []edit) {
+	aLen, bLen := 0, 0
+	for _, e := range hunk {
+		if e.op != '+' {
+			aLen++
+		}
+		if e.op != '-' {
+			bLen++
+		}
+	}
+	buffer.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(hunk[0].a, aLen), hunkRange(hunk[0].b, bLen)))
+	for _, e := range hunk {
+		buffer.WriteByte(e.op)
+		buffer.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			buffer.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(before, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, length)
+}
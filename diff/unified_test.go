@@ -0,0 +1,39 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedWithoutChanges(t *testing.T) {
+	assert.Equal(t, "", Unified("a", "b", "same\n", "same\n", DefaultContext))
+}
+
+func TestUnified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	expected := `--- remote/a.liquid
++++ local/a.liquid
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+\ No newline at end of file
+`
+	assert.Equal(t, expected, Unified("remote/a.liquid", "local/a.liquid", a, b, DefaultContext))
+}
+
+func TestUnifiedWithEmptySide(t *testing.T) {
+	expected := "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+new\n"
+	assert.Equal(t, expected, Unified("a", "b", "", "new\n", DefaultContext))
+}
@@ -1,12 +1,19 @@
 package themekit
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"sync"
+	"time"
 )
 
+// NetworkFailureExitCode is the exit status used when Shopify could not be reached or responded
+// with a server error
+const NetworkFailureExitCode = 4
+
 // ErrorReporter ... TODO
 type ErrorReporter interface {
 	Report(error)
@@ -32,7 +39,33 @@ func (h HaltExecutionReporter) Report(e error) {
 	c := ConsoleReporter{}
 	libraryInfo := fmt.Sprintf("%s%s%s", MessageSeparator, LibraryInfo(), MessageSeparator)
 	c.Report(errors.New(libraryInfo))
-	log.Fatal(e)
+	log.Print(e)
+	os.Exit(exitStatusFor(e))
+}
+
+// JSONReporter writes errors to stdout as JSON objects, in the format of EncodeEvent, and
+// halts execution like HaltExecutionReporter. Both exit with NetworkFailureExitCode when
+// Shopify could not be reached.
+type JSONReporter struct{}
+
+// Report writes the error and exits
+func (j JSONReporter) Report(e error) {
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":       "error",
+		"error":      e.Error(),
+		"successful": false,
+		"timestamp":  time.Now().Format(time.RFC3339Nano),
+	})
+	fmt.Println(string(data))
+	os.Exit(exitStatusFor(e))
+}
+
+// exitStatusFor returns the status to exit with after a fatal error
+func exitStatusFor(err error) int {
+	if IsNetworkFailure(err) {
+		return NetworkFailureExitCode
+	}
+	return 1
 }
 
 var reporter ErrorReporter = nullReporter{}
@@ -18,6 +18,7 @@ const configurationFilename = "config\\.yml"
 var defaultRegexes = []*re.Regexp{
 	re.MustCompile(`\.git/*`),
 	re.MustCompile(`\.DS_Store`),
+	re.MustCompile(`\.themekit/*`),
 }
 
 var defaultGlobs = []string{}
@@ -47,7 +47,7 @@ func TestFilterRemovesEmptyStrings(t *testing.T) {
 
 func TestDefaultFilters(t *testing.T) {
 	eventFilter := NewEventFilterFromReaders([]io.Reader{})
-	inputEvents := []string{".git/HEAD", ".DS_Store", "config.yml", "templates/products.liquid"}
+	inputEvents := []string{".git/HEAD", ".DS_Store", "config.yml", ".themekit/development.json", "templates/products.liquid"}
 	expectedEvents := []string{"templates/products.liquid"}
 	assertFilter(t, eventFilter, inputEvents, expectedEvents)
 }
@@ -7,18 +7,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-	"time"
 
 	"gopkg.in/fsnotify.v1"
 
 	"github.com/Shopify/themekit/theme"
 )
 
-const eventTimeoutInMs int64 = 3000
-
-var assetLocations = []string{"templates/customers/", "assets/", "config/", "layout/", "snippets/", "templates/", "locales/", "sections/"}
-
 // FsAssetEvent ... TODO
 type FsAssetEvent struct {
 	asset     theme.Asset
@@ -47,57 +43,157 @@ func (f FsAssetEvent) Type() EventType {
 
 // IsValid ... TODO
 func (f FsAssetEvent) IsValid() bool {
-	return f.eventType == Remove || f.asset.IsValid()
+	return len(f.asset.Key) > 0 && (f.eventType == Remove || f.asset.IsValid())
 }
 
 func (f FsAssetEvent) String() string {
 	return fmt.Sprintf("%s|%s", f.asset.Key, f.eventType.String())
 }
 
-// NewFileWatcher ... TODO
-func NewFileWatcher(dir string, recur bool, filter EventFilter) (chan AssetEvent, error) {
-	dirsToWatch, err := findDirectoriesToWatch(dir, recur, filter.MatchesFilter)
-	if err != nil {
-		return nil, err
-	}
-
+// NewFileWatcher watches dir, and its subdirectories when recur, for changes to the files not
+// matching the filter. Problems met while watching are sent on errs, which must be drained.
+// Closing stop closes the watcher, and then both returned channels.
+func NewFileWatcher(dir string, recur bool, filter EventFilter, stop chan bool) (events chan AssetEvent, errs chan error, err error) {
 	watcher, err := fsnotify.NewWatcher()
-	// TODO: the watcher should be closed at the end!!
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	for _, path := range dirsToWatch {
-		if err := watcher.Add(path); err != nil {
-			return nil, fmt.Errorf("Could not watch directory %s: %s", path, err)
-		}
+	tree := newWatchedTree(watcher, recur, filter)
+	if _, err := tree.add(dir); err != nil {
+		watcher.Close()
+		return nil, nil, err
 	}
 
-	return convertFsEvents(watcher.Events, filter), nil
+	go func() {
+		<-stop
+		watcher.Close()
+	}()
+	events, errs = convertFsEvents(watcher.Events, watcher.Errors, tree)
+	return events, errs, nil
+}
+
+type directoryWatcher interface {
+	Add(path string) error
+	Remove(path string) error
+}
+
+// watchedTree keeps track of the directories being watched, and of the files within them, so
+// that directories created, moved or removed while watching are followed.
+type watchedTree struct {
+	watcher     directoryWatcher
+	recursive   bool
+	filter      EventFilter
+	directories map[string]bool
+	files       map[string]bool
 }
 
-func findDirectoriesToWatch(start string, recursive bool, ignoreDirectory func(string) bool) ([]string, error) {
-	var result []string
-	if !recursive {
-		result = append(result, start)
-		return result, nil
+func newWatchedTree(watcher directoryWatcher, recursive bool, filter EventFilter) *watchedTree {
+	return &watchedTree{
+		watcher:     watcher,
+		recursive:   recursive,
+		filter:      filter,
+		directories: map[string]bool{},
+		files:       map[string]bool{},
 	}
+}
 
+// add starts watching a directory, and its subdirectories when recursive. It returns the files
+// found within them.
+func (t *watchedTree) add(start string) ([]string, error) {
+	var found []string
 	walkFunc := func(path string, info os.FileInfo, err error) error {
-		if !info.IsDir() {
+		if os.IsNotExist(err) {
+			// removed while being walked, its events will follow
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if path != start && t.filter.MatchesFilter(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
 			return nil
 		}
-		if ignoreDirectory(path) {
+		if !info.IsDir() {
+			t.files[path] = true
+			found = append(found, path)
 			return nil
 		}
-		result = append(result, path)
+		if path != start && !t.recursive {
+			return filepath.SkipDir
+		}
+		if err := t.watcher.Add(path); os.IsNotExist(err) {
+			return filepath.SkipDir
+		} else if err != nil {
+			return fmt.Errorf("Could not watch directory %s: %s", path, err)
+		}
+		t.directories[path] = true
 		return nil
 	}
-	if err := filepath.Walk(start, walkFunc); err != nil {
-		return nil, err
+	return found, filepath.Walk(start, walkFunc)
+}
+
+// remove stops watching a directory that was removed or moved away, and returns the files that
+// were within it.
+func (t *watchedTree) remove(dir string) []string {
+	var removed []string
+	prefix := dir + string(filepath.Separator)
+	for path := range t.directories {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			t.watcher.Remove(path)
+			delete(t.directories, path)
+		}
+	}
+	for path := range t.files {
+		if strings.HasPrefix(path, prefix) {
+			removed = append(removed, path)
+			delete(t.files, path)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// handle converts a filesystem event into the asset events it implies. Renames are reported by
+// fsnotify as a Rename of the old name followed by a Create of the new one, so they become a
+// removal and an update. Removals of paths that are not being tracked, like the second event
+// inotify sends for a removed directory, are dropped.
+func (t *watchedTree) handle(event fsnotify.Event) ([]FsAssetEvent, error) {
+	switch {
+	case event.Op&fsnotify.Remove == fsnotify.Remove, event.Op&fsnotify.Rename == fsnotify.Rename:
+		if t.directories[event.Name] {
+			return handleAll(t.remove(event.Name), fsnotify.Remove), nil
+		}
+		if !t.files[event.Name] {
+			return nil, nil
+		}
+		delete(t.files, event.Name)
+	case event.Op&fsnotify.Create == fsnotify.Create && isDirectory(event.Name):
+		if !t.recursive {
+			return nil, nil
+		}
+		files, err := t.add(event.Name)
+		return handleAll(files, fsnotify.Create), err
+	default:
+		t.files[event.Name] = true
+	}
+	return []FsAssetEvent{HandleEvent(event)}, nil
+}
+
+func handleAll(paths []string, op fsnotify.Op) []FsAssetEvent {
+	events := make([]FsAssetEvent, len(paths))
+	for i, path := range paths {
+		events[i] = HandleEvent(fsnotify.Event{Name: path, Op: op})
 	}
-	return result, nil
+	return events
 }
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 func fwLoadAsset(event fsnotify.Event) theme.Asset {
 	root := filepath.Dir(event.Name)
 	filename := filepath.Base(event.Name)
@@ -118,11 +214,11 @@ func fwLoadAsset(event fsnotify.Event) theme.Asset {
 func HandleEvent(event fsnotify.Event) FsAssetEvent {
 	var eventType EventType
 	asset := fwLoadAsset(event)
-	switch event.Op {
-	case fsnotify.Create:
-		eventType = Update
-	case fsnotify.Remove:
+	switch {
+	case event.Op&fsnotify.Remove == fsnotify.Remove, event.Op&fsnotify.Rename == fsnotify.Rename:
 		eventType = Remove
+	case event.Op&fsnotify.Create == fsnotify.Create, event.Op&fsnotify.Write == fsnotify.Write:
+		eventType = Update
 	}
 	return FsAssetEvent{asset: asset, eventType: eventType}
 }
@@ -145,7 +241,7 @@ func Encode64(data []byte) string {
 func extractAssetKey(filename string) string {
 	filename = filepath.ToSlash(filename)
 
-	for _, dir := range assetLocations {
+	for _, dir := range theme.AssetLocations {
 		split := strings.SplitAfterN(filename, dir, 2)
 		if len(split) > 1 {
 			return fmt.Sprintf("%s%s", dir, split[len(split)-1])
@@ -154,29 +250,40 @@ func extractAssetKey(filename string) string {
 	return ""
 }
 
-func convertFsEvents(events chan fsnotify.Event, filter EventFilter) chan AssetEvent {
+func convertFsEvents(events chan fsnotify.Event, watchErrors chan error, tree *watchedTree) (chan AssetEvent, chan error) {
 	results := make(chan AssetEvent)
+	errs := make(chan error)
 	go func() {
-		duplicateEventTimeout := map[string]int64{}
-		for {
-			event := <-events
-
-			if event.Op == fsnotify.Chmod {
-				continue
-			}
-
-			// TODO: we should add new directories to the watch list
-			if !filter.MatchesFilter(event.Name) {
-				fsevent := HandleEvent(event)
-				duplicateEventTimeoutKey := fsevent.String()
-				timestamp := (time.Now().UnixNano() / int64(time.Millisecond))
+		for events != nil || watchErrors != nil {
+			select {
+			case err, more := <-watchErrors:
+				if !more {
+					watchErrors = nil
+				} else {
+					errs <- err
+				}
+			case event, more := <-events:
+				if !more {
+					events = nil
+					continue
+				}
+				if event.Op == fsnotify.Chmod || tree.filter.MatchesFilter(event.Name) {
+					continue
+				}
 
-				if duplicateEventTimeout[duplicateEventTimeoutKey] < timestamp && fsevent.IsValid() {
-					duplicateEventTimeout[duplicateEventTimeoutKey] = timestamp + eventTimeoutInMs
-					results <- fsevent
+				fsevents, err := tree.handle(event)
+				if err != nil {
+					errs <- err
+				}
+				for _, fsevent := range fsevents {
+					if fsevent.IsValid() {
+						results <- fsevent
+					}
 				}
 			}
 		}
+		close(results)
+		close(errs)
 	}()
-	return results
+	return results, errs
 }
@@ -2,6 +2,8 @@ package themekit
 
 import (
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -59,9 +61,14 @@ func (s *FileWatcherSuite) TestHandleEventConvertsFSNotifyEventsIntoAssetEvents(
 		return []byte("hello"), nil
 	}
 	writes := map[fsnotify.Op]EventType{
-		fsnotify.Chmod:  Update,
-		fsnotify.Create: Update,
-		fsnotify.Remove: Remove,
+		fsnotify.Chmod:                   Update,
+		fsnotify.Create:                  Update,
+		fsnotify.Write:                   Update,
+		fsnotify.Remove:                  Remove,
+		fsnotify.Rename:                  Remove,
+		fsnotify.Create | fsnotify.Write: Update,
+		fsnotify.Remove | fsnotify.Write: Remove,
+		fsnotify.Rename | fsnotify.Chmod: Remove,
 	}
 	for fsEvent, themekitEvent := range writes {
 		event := fsnotify.Event{Name: "fixtures/whatever.txt", Op: fsEvent}
@@ -70,6 +77,79 @@ func (s *FileWatcherSuite) TestHandleEventConvertsFSNotifyEventsIntoAssetEvents(
 	}
 }
 
+func (s *FileWatcherSuite) TestFollowingDirectoriesCreatedAndMovedWhileWatching() {
+	root, _ := ioutil.TempDir("", "watched")
+	defer os.RemoveAll(root)
+	os.MkdirAll(filepath.Join(root, "snippets"), 0755)
+	watcher := &fakeDirectoryWatcher{watched: map[string]bool{}}
+	tree := newWatchedTree(watcher, true, NewEventFilter([]string{}))
+	tree.add(root)
+
+	folder := filepath.Join(root, "snippets", "new-folder")
+	os.MkdirAll(filepath.Join(folder, "nested"), 0755)
+	ioutil.WriteFile(filepath.Join(folder, "nested", "card.liquid"), []byte("card"), 0644)
+	events, err := tree.handle(fsnotify.Event{Name: folder, Op: fsnotify.Create})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, len(events))
+	assert.Equal(s.T(), "snippets/new-folder/nested/card.liquid|Update", events[0].String())
+	assert.True(s.T(), watcher.watched[filepath.Join(folder, "nested")])
+
+	os.Rename(folder, filepath.Join(root, "moved"))
+	events, _ = tree.handle(fsnotify.Event{Name: folder, Op: fsnotify.Rename})
+	assert.Equal(s.T(), 1, len(events))
+	assert.Equal(s.T(), "snippets/new-folder/nested/card.liquid|Remove", events[0].String())
+	assert.True(s.T(), events[0].IsValid())
+	assert.False(s.T(), watcher.watched[folder])
+	assert.False(s.T(), watcher.watched[filepath.Join(folder, "nested")])
+
+	events, _ = tree.handle(fsnotify.Event{Name: folder, Op: fsnotify.Rename})
+	assert.Equal(s.T(), 0, len(events), "The event inotify sends on the moved directory itself is dropped")
+}
+
+func (s *FileWatcherSuite) TestIgnoringDirectoriesRemovedWhileBeingAdded() {
+	root, _ := ioutil.TempDir("", "watched")
+	defer os.RemoveAll(root)
+	tree := newWatchedTree(&fakeDirectoryWatcher{watched: map[string]bool{}}, true, NewEventFilter([]string{}))
+	tree.add(root)
+
+	files, err := tree.add(filepath.Join(root, "snippets", "tmp"))
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 0, len(files))
+}
+
+func (s *FileWatcherSuite) TestIgnoringRemovalsOutsideOfAssetLocations() {
+	event := HandleEvent(fsnotify.Event{Name: "fixtures/notes.txt", Op: fsnotify.Remove})
+	assert.False(s.T(), event.IsValid())
+}
+
+func (s *FileWatcherSuite) TestStoppingTheFileWatcher() {
+	root, _ := ioutil.TempDir("", "watched")
+	defer os.RemoveAll(root)
+	stop := make(chan bool)
+	events, errs, err := NewFileWatcher(root, true, NewEventFilter([]string{}), stop)
+	assert.Nil(s.T(), err)
+
+	close(stop)
+	_, more := <-events
+	assert.False(s.T(), more, "Stopping closes the events")
+	_, more = <-errs
+	assert.False(s.T(), more, "Stopping closes the errors")
+}
+
+type fakeDirectoryWatcher struct {
+	watched map[string]bool
+}
+
+func (f *fakeDirectoryWatcher) Add(path string) error {
+	f.watched[path] = true
+	return nil
+}
+
+func (f *fakeDirectoryWatcher) Remove(path string) error {
+	delete(f.watched, path)
+	return nil
+}
+
 func TestFileWatcherSuite(t *testing.T) {
 	suite.Run(t, new(FileWatcherSuite))
 }
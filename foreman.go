@@ -1,6 +1,7 @@
 package themekit
 
 import (
+	"sync"
 	"time"
 
 	"github.com/Shopify/themekit/bucket"
@@ -26,19 +27,32 @@ func NewForeman(leakyBucket *bucket.LeakyBucket) Foreman {
 	}
 }
 
-// IssueWork ... TODO
+// IssueWork hands the jobs of the JobQueue out on the WorkerQueue as the leaky bucket allows.
+// Closing the JobQueue closes the WorkerQueue once every job has been handed out.
 func (f Foreman) IssueWork() {
 	f.leakyBucket.StartDripping()
 	go func() {
+		var issued sync.WaitGroup
 		notifyProcessed := false
 		for {
 			select {
-			case job := <-f.JobQueue:
+			case job, more := <-f.JobQueue:
+				if !more {
+					// no more work will be issued, close the worker queue once every job is handed out
+					f.leakyBucket.StopDripping()
+					go func() {
+						issued.Wait()
+						close(f.WorkerQueue)
+					}()
+					return
+				}
 				f.leakyBucket.GetDrop()
 				notifyProcessed = true
+				issued.Add(1)
 				// TODO: this was a potential aliasing issue!
 				go func(jobToAdd AssetEvent) {
 					f.WorkerQueue <- jobToAdd
+					issued.Done()
 				}(job)
 			case <-f.halt:
 				return
@@ -1,3 +1,28 @@
 package themekit
 
-// TODO
+import (
+	"testing"
+
+	"github.com/Shopify/themekit/bucket"
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosingTheJobQueueClosesTheWorkerQueue(t *testing.T) {
+	leakyBucket := bucket.NewLeakyBucket(10, 10, 1)
+	leakyBucket.TopUp()
+	foreman := NewForeman(leakyBucket)
+	foreman.IssueWork()
+
+	go func() {
+		foreman.JobQueue <- NewUploadEvent(theme.Asset{Key: "templates/404.liquid"})
+		foreman.JobQueue <- NewUploadEvent(theme.Asset{Key: "templates/index.liquid"})
+		close(foreman.JobQueue)
+	}()
+
+	keys := []string{}
+	for job := range foreman.WorkerQueue {
+		keys = append(keys, job.Asset().Key)
+	}
+	assert.Equal(t, 2, len(keys))
+}
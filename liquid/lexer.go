@@ -0,0 +1,176 @@
+// Package liquid checks the syntax of Liquid templates without rendering them
+package liquid
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TokenKind distinguishes plain text from Liquid markup
+type TokenKind int
+
+const (
+	// Text is content outside of any Liquid markup
+	Text TokenKind = iota
+	// Output is an output tag, {{ ... }}
+	Output
+	// Tag is a logic tag, {% ... %}
+	Tag
+)
+
+// rawTags have bodies that are not Liquid, so their contents are kept as text
+var rawTags = map[string]bool{
+	"raw":        true,
+	"comment":    true,
+	"schema":     true,
+	"javascript": true,
+	"stylesheet": true,
+}
+
+// Token is a piece of a template. The Markup of outputs and tags excludes their delimiters
+// and whitespace control characters.
+type Token struct {
+	Kind   TokenKind
+	Markup string
+	Line   int
+	Column int
+}
+
+// Name returns the name of a tag, such as "if" for {% if product.available %}
+func (t Token) Name() string {
+	if fields := strings.Fields(t.Markup); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// Arguments returns the markup of a tag following its name
+func (t Token) Arguments() string {
+	return strings.TrimSpace(strings.TrimPrefix(t.Markup, t.Name()))
+}
+
+// Error is a problem found in a template, positioned at the markup that caused it
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+type lexer struct {
+	source     string
+	lineStarts []int
+	tokens     []Token
+	errors     []Error
+}
+
+// Tokenize splits a template into text, outputs and tags, reporting markup that is not terminated
+func Tokenize(source string) ([]Token, []Error) {
+	l := &lexer{source: source, lineStarts: []int{0}}
+	for i, c := range source {
+		if c == '\n' {
+			l.lineStarts = append(l.lineStarts, i+1)
+		}
+	}
+	l.run()
+	return l.tokens, l.errors
+}
+
+func (l *lexer) run() {
+	pos := 0
+	for pos < len(l.source) {
+		start := nextOpening(l.source, pos)
+		if start < 0 {
+			l.emit(Text, l.source[pos:], pos)
+			return
+		}
+		if start > pos {
+			l.emit(Text, l.source[pos:start], pos)
+		}
+
+		kind, closing := Output, "}}"
+		if l.source[start+1] == '%' {
+			kind, closing = Tag, "%}"
+		}
+		end := strings.Index(l.source[start+2:], closing)
+		next := nextOpening(l.source, start+2)
+		if end < 0 || (next >= 0 && next < start+2+end) {
+			l.report(start, fmt.Sprintf("%s is not terminated with '%s'", describeOpening(kind), closing))
+			if next < 0 {
+				return
+			}
+			pos = next
+			continue
+		}
+
+		end += start + 2
+		token := l.emit(kind, trimMarkup(l.source[start+2:end]), start)
+		pos = end + 2
+		if kind == Tag && rawTags[token.Name()] {
+			pos = l.skipRawBody(token, pos)
+		}
+	}
+}
+
+// skipRawBody keeps the body of a raw tag as text and returns the position after its end tag
+func (l *lexer) skipRawBody(opening Token, pos int) int {
+	endTag := regexp.MustCompile(`\{%-?\s*end` + opening.Name() + `\s*-?%\}`)
+	match := endTag.FindStringIndex(l.source[pos:])
+	if match == nil {
+		l.emit(Text, l.source[pos:], pos)
+		return len(l.source)
+	}
+	if match[0] > 0 {
+		l.emit(Text, l.source[pos:pos+match[0]], pos)
+	}
+	l.emit(Tag, "end"+opening.Name(), pos+match[0])
+	return pos + match[1]
+}
+
+func (l *lexer) emit(kind TokenKind, markup string, offset int) Token {
+	line, column := l.position(offset)
+	token := Token{Kind: kind, Markup: markup, Line: line, Column: column}
+	l.tokens = append(l.tokens, token)
+	return token
+}
+
+func (l *lexer) report(offset int, message string) {
+	line, column := l.position(offset)
+	l.errors = append(l.errors, Error{Line: line, Column: column, Message: message})
+}
+
+func (l *lexer) position(offset int) (line, column int) {
+	index := sort.Search(len(l.lineStarts), func(i int) bool { return l.lineStarts[i] > offset }) - 1
+	return index + 1, offset - l.lineStarts[index] + 1
+}
+
+func nextOpening(source string, from int) int {
+	output := strings.Index(source[from:], "{{")
+	tag := strings.Index(source[from:], "{%")
+	switch {
+	case output < 0 && tag < 0:
+		return -1
+	case output < 0 || (tag >= 0 && tag < output):
+		return from + tag
+	default:
+		return from + output
+	}
+}
+
+func trimMarkup(markup string) string {
+	markup = strings.TrimPrefix(markup, "-")
+	markup = strings.TrimSuffix(markup, "-")
+	return strings.TrimSpace(markup)
+}
+
+func describeOpening(kind TokenKind) string {
+	if kind == Output {
+		return "output '{{'"
+	}
+	return "tag '{%'"
+}
@@ -0,0 +1,232 @@
+package liquid
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// blockTags maps the tags that must be closed with an end tag to the tags allowed directly within them
+var blockTags = map[string][]string{
+	"if":         {"elsif", "else"},
+	"unless":     {"elsif", "else"},
+	"case":       {"when", "else"},
+	"for":        {"else"},
+	"tablerow":   {},
+	"capture":    {},
+	"form":       {},
+	"paginate":   {},
+	"style":      {},
+	"raw":        {},
+	"comment":    {},
+	"schema":     {},
+	"javascript": {},
+	"stylesheet": {},
+}
+
+// argumentTags are the tags that cannot be used without arguments
+var argumentTags = map[string]string{
+	"if":       "a condition",
+	"unless":   "a condition",
+	"elsif":    "a condition",
+	"case":     "a variable",
+	"when":     "a value",
+	"for":      "a loop",
+	"tablerow": "a loop",
+	"capture":  "a variable",
+	"assign":   "a variable",
+	"include":  "a snippet",
+	"section":  "a section",
+	"render":   "a snippet",
+	"paginate": "a collection",
+	"form":     "a form type",
+}
+
+var filterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Lint checks the syntax of a template, reporting unbalanced block tags, markup that is not
+// terminated and invalid filters
+func Lint(source string) []Error {
+	tokens, errors := Tokenize(source)
+	stack := []Token{}
+	report := func(token Token, format string, args ...interface{}) {
+		errors = append(errors, Error{Line: token.Line, Column: token.Column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, token := range tokens {
+		switch token.Kind {
+		case Output:
+			for _, problem := range lintFilters(token.Markup, true) {
+				report(token, "%s", problem)
+			}
+		case Tag:
+			name := token.Name()
+			if len(name) == 0 {
+				report(token, "tag is empty")
+				continue
+			}
+			if what, found := argumentTags[name]; found && len(token.Arguments()) == 0 {
+				report(token, "'%s' requires %s", name, what)
+			}
+
+			switch {
+			case name == "assign":
+				if parts := strings.SplitN(token.Arguments(), "=", 2); len(parts) == 2 {
+					for _, problem := range lintFilters(parts[1], false) {
+						report(token, "%s", problem)
+					}
+				} else if len(token.Arguments()) > 0 {
+					report(token, "'assign' requires a value after '='")
+				}
+			case name == "echo":
+				for _, problem := range lintFilters(token.Arguments(), false) {
+					report(token, "%s", problem)
+				}
+			case isBlock(name):
+				stack = append(stack, token)
+			case strings.HasPrefix(name, "end"):
+				stack = closeBlock(stack, token, report)
+			case name == "else" || name == "elsif" || name == "when":
+				if len(stack) == 0 || !allowedWithin(stack[len(stack)-1].Name(), name) {
+					report(token, "'%s' is not within a block that allows it", name)
+				}
+			case name == "break" || name == "continue":
+				if !withinLoop(stack) {
+					report(token, "'%s' is not within a loop", name)
+				}
+			}
+		}
+	}
+
+	for _, block := range stack {
+		report(block, "'%s' is not closed with 'end%s'", block.Name(), block.Name())
+	}
+	sort.Stable(byPosition(errors))
+	return errors
+}
+
+// closeBlock pops the block closed by an end tag, reporting the blocks within it that were left open
+func closeBlock(stack []Token, token Token, report func(Token, string, ...interface{})) []Token {
+	name := strings.TrimPrefix(token.Name(), "end")
+	if !isBlock(name) {
+		report(token, "'%s' does not close any block", token.Name())
+		return stack
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].Name() != name {
+			continue
+		}
+		for _, unclosed := range stack[i+1:] {
+			report(unclosed, "'%s' is not closed before 'end%s' on line %d", unclosed.Name(), name, token.Line)
+		}
+		return stack[:i]
+	}
+	report(token, "'%s' has no matching '%s'", token.Name(), name)
+	return stack
+}
+
+// lintFilters checks an expression followed by filters, such as product.title | upcase | truncate: 20
+func lintFilters(markup string, allowEmpty bool) []string {
+	if len(strings.TrimSpace(markup)) == 0 {
+		if allowEmpty {
+			return nil
+		}
+		return []string{"missing a value"}
+	}
+	parts, terminated := splitOutsideQuotes(markup, '|')
+	if !terminated {
+		return []string{"string is not terminated"}
+	}
+
+	problems := []string{}
+	if len(strings.TrimSpace(parts[0])) == 0 {
+		problems = append(problems, "filter is not applied to a value")
+	}
+	for _, filter := range parts[1:] {
+		filter = strings.TrimSpace(filter)
+		if len(filter) == 0 {
+			problems = append(problems, "empty filter after '|'")
+			continue
+		}
+		nameAndArguments := strings.SplitN(filter, ":", 2)
+		name := strings.TrimSpace(nameAndArguments[0])
+		if !filterName.MatchString(name) {
+			problems = append(problems, fmt.Sprintf("'%s' is not a valid filter name", name))
+			continue
+		}
+		if len(nameAndArguments) < 2 {
+			continue
+		}
+		arguments, _ := splitOutsideQuotes(nameAndArguments[1], ',')
+		for _, argument := range arguments {
+			if len(strings.TrimSpace(argument)) == 0 {
+				problems = append(problems, fmt.Sprintf("filter '%s' has a missing argument", name))
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// splitOutsideQuotes splits s on sep where it is not within a quoted string, and reports
+// whether every string was terminated
+func splitOutsideQuotes(s string, sep rune) ([]string, bool) {
+	parts := []string{}
+	var quote rune
+	start := 0
+	for i, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:]), quote == 0
+}
+
+func isBlock(name string) bool {
+	_, found := blockTags[name]
+	return found
+}
+
+func allowedWithin(block, name string) bool {
+	for _, allowed := range blockTags[block] {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+func withinLoop(stack []Token) bool {
+	for _, block := range stack {
+		if name := block.Name(); name == "for" || name == "tablerow" {
+			return true
+		}
+	}
+	return false
+}
+
+type byPosition []Error
+
+func (e byPosition) Len() int {
+	return len(e)
+}
+
+func (e byPosition) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+}
+
+func (e byPosition) Less(i, j int) bool {
+	if e[i].Line != e[j].Line {
+		return e[i].Line < e[j].Line
+	}
+	return e[i].Column < e[j].Column
+}
@@ -0,0 +1,83 @@
+package liquid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizingATemplate(t *testing.T) {
+	tokens, errors := Tokenize("<h1>{{- product.title -}}</h1>\n{% if product.available %}Buy{% endif %}")
+	assert.Equal(t, 0, len(errors))
+	assert.Equal(t, []Token{
+		{Kind: Text, Markup: "<h1>", Line: 1, Column: 1},
+		{Kind: Output, Markup: "product.title", Line: 1, Column: 5},
+		{Kind: Text, Markup: "</h1>\n", Line: 1, Column: 26},
+		{Kind: Tag, Markup: "if product.available", Line: 2, Column: 1},
+		{Kind: Text, Markup: "Buy", Line: 2, Column: 27},
+		{Kind: Tag, Markup: "endif", Line: 2, Column: 30},
+	}, tokens)
+}
+
+func TestRawTagsAreNotParsed(t *testing.T) {
+	assert.Equal(t, []Error(nil), Lint("{% raw %}{{ not liquid {% endraw %}{% schema %}{\"name\": \"{% if\"}{% endschema %}"))
+}
+
+func TestLintingAValidTemplate(t *testing.T) {
+	template := `{% assign title = product.title | upcase | truncate: 20, '...' %}
+{% for variant in product.variants %}
+  {% if variant.available %}{{ variant.price | money }}{% elsif forloop.last %}{% break %}{% else %}-{% endif %}
+{% else %}
+  {% case product.type %}{% when 'shirt' %}Shirt{% else %}Other{% endcase %}
+{% endfor %}
+{% capture message %}{{ "it's | here" }}{% endcapture %}`
+	assert.Equal(t, []Error(nil), Lint(template))
+}
+
+func TestLintingUnbalancedTags(t *testing.T) {
+	errors := Lint("{% if a %}\n{% for b in c %}\n{% endif %}\n{% endfor %}\n{% capture x %}")
+	assert.Equal(t, []Error{
+		{Line: 2, Column: 1, Message: "'for' is not closed before 'endif' on line 3"},
+		{Line: 4, Column: 1, Message: "'endfor' has no matching 'for'"},
+		{Line: 5, Column: 1, Message: "'capture' is not closed with 'endcapture'"},
+	}, errors)
+}
+
+func TestLintingMisplacedTags(t *testing.T) {
+	errors := Lint("{% else %}{% break %}{% endthing %}{% if %}{% endif %}")
+	assert.Equal(t, []string{
+		"'else' is not within a block that allows it",
+		"'break' is not within a loop",
+		"'endthing' does not close any block",
+		"'if' requires a condition",
+	}, messages(errors))
+}
+
+func TestLintingUnterminatedMarkup(t *testing.T) {
+	errors := Lint("<p>{{ product.title </p>\n{% if a %}{{ b }}{% endif")
+	assert.Equal(t, []Error{
+		{Line: 1, Column: 4, Message: "output '{{' is not terminated with '}}'"},
+		{Line: 2, Column: 1, Message: "'if' is not closed with 'endif'"},
+		{Line: 2, Column: 18, Message: "tag '{%' is not terminated with '%}'"},
+	}, errors)
+}
+
+func TestLintingFilters(t *testing.T) {
+	errors := Lint("{{ a | }}{{ | upcase }}{{ a | 9lives }}{{ a | truncate: 20, }}{{ 'open | upcase }}{% assign b = %}")
+	assert.Equal(t, []string{
+		"empty filter after '|'",
+		"filter is not applied to a value",
+		"'9lives' is not a valid filter name",
+		"filter 'truncate' has a missing argument",
+		"string is not terminated",
+		"missing a value",
+	}, messages(errors))
+}
+
+func messages(errors []Error) []string {
+	result := []string{}
+	for _, err := range errors {
+		result = append(result, err.Message)
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+package themekit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Shopify/themekit/theme"
+)
+
+// ManifestDirectory is the directory, next to config.yml, where themekit keeps its sync state
+const ManifestDirectory = ".themekit"
+
+// ManifestEntry records the state of an asset the last time it was synced with Shopify
+type ManifestEntry struct {
+	Checksum  string `json:"checksum"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// NewManifestEntry builds the entry for an asset that was just synced. The remote
+// checksum is used when available, otherwise the digest of the asset contents.
+func NewManifestEntry(asset theme.Asset) ManifestEntry {
+	checksum := asset.Checksum
+	if len(checksum) == 0 {
+		checksum = asset.Digest()
+	}
+	return ManifestEntry{Checksum: checksum, UpdatedAt: asset.UpdatedAt}
+}
+
+// Manifest tracks what was last pushed to or pulled from a theme for a single environment.
+// Alongside the manifest file it keeps a base copy of every synced text asset, used for
+// three-way merges. It is safe for concurrent use.
+type Manifest struct {
+	path    string
+	mutex   *sync.Mutex
+	entries map[string]ManifestEntry
+}
+
+type manifestFile struct {
+	Assets map[string]ManifestEntry `json:"assets"`
+}
+
+// ManifestPath returns the location of the manifest for an environment
+func ManifestPath(dir, environment string) string {
+	return filepath.Join(dir, ManifestDirectory, environment+".json")
+}
+
+// NewManifest returns an empty manifest that will be saved to path. A manifest
+// with an empty path is kept in memory only.
+func NewManifest(path string) *Manifest {
+	return &Manifest{path: path, mutex: &sync.Mutex{}, entries: map[string]ManifestEntry{}}
+}
+
+// LoadManifest reads the manifest for an environment, returning an empty one if
+// nothing has been synced yet.
+func LoadManifest(dir, environment string) (*Manifest, error) {
+	manifest := NewManifest(ManifestPath(dir, environment))
+	contents, err := ioutil.ReadFile(manifest.path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	} else if err != nil {
+		return manifest, err
+	}
+
+	var file manifestFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return manifest, err
+	}
+	if file.Assets != nil {
+		manifest.entries = file.Assets
+	}
+	return manifest, nil
+}
+
+// Get returns the entry recorded for an asset key
+func (m *Manifest) Get(key string) (entry ManifestEntry, found bool) {
+	synchronized(m.mutex, func() {
+		entry, found = m.entries[key]
+	})
+	return
+}
+
+// Keys returns every asset key in the manifest
+func (m *Manifest) Keys() []string {
+	keys := []string{}
+	synchronized(m.mutex, func() {
+		for key := range m.entries {
+			keys = append(keys, key)
+		}
+	})
+	return keys
+}
+
+// Set records the state of an asset key
+func (m *Manifest) Set(key string, entry ManifestEntry) {
+	synchronized(m.mutex, func() {
+		m.entries[key] = entry
+	})
+}
+
+// Delete forgets an asset key
+func (m *Manifest) Delete(key string) {
+	synchronized(m.mutex, func() {
+		delete(m.entries, key)
+	})
+}
+
+// Record updates the manifest from the outcome of an API operation. Events
+// that are not successful asset operations are ignored.
+func (m *Manifest) Record(event ThemeEvent) {
+	assetEvent, ok := event.(APIAssetEvent)
+	if !ok || !assetEvent.Successful() {
+		return
+	}
+	if assetEvent.EventType == Remove.String() {
+		m.Delete(assetEvent.AssetKey)
+		m.RemoveBase(assetEvent.AssetKey)
+	} else {
+		m.Set(assetEvent.AssetKey, ManifestEntry{Checksum: assetEvent.Checksum, UpdatedAt: assetEvent.UpdatedAt})
+		m.SaveBase(assetEvent.asset)
+	}
+}
+
+// SaveBase keeps a copy of a text asset as it was synced. Binary assets are not kept.
+func (m *Manifest) SaveBase(asset theme.Asset) error {
+	if len(m.path) == 0 || len(asset.Value) == 0 {
+		return nil
+	}
+	return writeFileAtomically(m.basePath(asset.Key), []byte(asset.Value))
+}
+
+// LoadBase returns the copy of a text asset as it was last synced
+func (m *Manifest) LoadBase(key string) (string, bool) {
+	if len(m.path) == 0 {
+		return "", false
+	}
+	contents, err := ioutil.ReadFile(m.basePath(key))
+	return string(contents), err == nil
+}
+
+// RemoveBase forgets the base copy of an asset
+func (m *Manifest) RemoveBase(key string) {
+	if len(m.path) > 0 {
+		os.Remove(m.basePath(key))
+	}
+}
+
+func (m *Manifest) basePath(key string) string {
+	return filepath.Join(strings.TrimSuffix(m.path, ".json"), "base", filepath.FromSlash(key))
+}
+
+// Save atomically writes the manifest to disk by replacing it with a fully written temporary file
+func (m *Manifest) Save() (err error) {
+	if len(m.path) == 0 {
+		return nil
+	}
+	synchronized(m.mutex, func() {
+		var data []byte
+		if data, err = json.MarshalIndent(manifestFile{Assets: m.entries}, "", "  "); err != nil {
+			return
+		}
+		err = writeFileAtomically(m.path, data)
+	})
+	return
+}
+
+func writeFileAtomically(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(dir, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
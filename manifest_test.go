@@ -0,0 +1,85 @@
+package themekit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/themekit/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadingAMissingManifest(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "themekit-manifest")
+	defer os.RemoveAll(dir)
+
+	manifest, err := LoadManifest(dir, "development")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(manifest.Keys()))
+}
+
+func TestSavingAndReloadingAManifest(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "themekit-manifest")
+	defer os.RemoveAll(dir)
+
+	manifest, _ := LoadManifest(dir, "development")
+	manifest.Set("layout/theme.liquid", ManifestEntry{Checksum: "abc", UpdatedAt: "2016-06-01T10:00:00-04:00"})
+	assert.Nil(t, manifest.Save())
+	assert.Equal(t, filepath.Join(dir, ".themekit", "development.json"), ManifestPath(dir, "development"))
+
+	reloaded, err := LoadManifest(dir, "development")
+	assert.Nil(t, err)
+	entry, found := reloaded.Get("layout/theme.liquid")
+	assert.True(t, found)
+	assert.Equal(t, "abc", entry.Checksum)
+	assert.Equal(t, "2016-06-01T10:00:00-04:00", entry.UpdatedAt)
+
+	files, _ := ioutil.ReadDir(filepath.Join(dir, ".themekit"))
+	assert.Equal(t, 1, len(files), "temporary files should not be left behind")
+}
+
+func TestRecordingAPIAssetEventsInTheManifest(t *testing.T) {
+	manifest := NewManifest("")
+	manifest.Set("snippets/old.liquid", ManifestEntry{Checksum: "old"})
+
+	manifest.Record(APIAssetEvent{AssetKey: "snippets/new.liquid", EventType: Update.String(), Code: 200, Checksum: "new"})
+	manifest.Record(APIAssetEvent{AssetKey: "snippets/old.liquid", EventType: Remove.String(), Code: 200})
+	manifest.Record(APIAssetEvent{AssetKey: "snippets/failed.liquid", EventType: Update.String(), Code: 422})
+	manifest.Record(NoOpEvent{})
+
+	entry, found := manifest.Get("snippets/new.liquid")
+	assert.True(t, found)
+	assert.Equal(t, "new", entry.Checksum)
+	_, found = manifest.Get("snippets/old.liquid")
+	assert.False(t, found)
+	_, found = manifest.Get("snippets/failed.liquid")
+	assert.False(t, found)
+}
+
+func TestKeepingBaseCopiesOfTextAssets(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "themekit-manifest")
+	defer os.RemoveAll(dir)
+	manifest, _ := LoadManifest(dir, "development")
+
+	manifest.SaveBase(theme.Asset{Key: "templates/customers/account.liquid", Value: "Account Page"})
+	manifest.SaveBase(theme.Asset{Key: "assets/image.png", Attachment: "aGVsbG8="})
+
+	base, found := manifest.LoadBase("templates/customers/account.liquid")
+	assert.True(t, found)
+	assert.Equal(t, "Account Page", base)
+	_, found = manifest.LoadBase("assets/image.png")
+	assert.False(t, found, "binary assets should not have a base copy")
+
+	manifest.Record(APIAssetEvent{AssetKey: "templates/customers/account.liquid", EventType: Remove.String(), Code: 200})
+	_, found = manifest.LoadBase("templates/customers/account.liquid")
+	assert.False(t, found)
+}
+
+func TestNewManifestEntryFallsBackToTheAssetDigest(t *testing.T) {
+	asset := theme.Asset{Key: "assets/hello.txt", Value: "hello world"}
+	assert.Equal(t, asset.Digest(), NewManifestEntry(asset).Checksum)
+
+	asset.Checksum = "remote"
+	assert.Equal(t, "remote", NewManifestEntry(asset).Checksum)
+}
@@ -0,0 +1,123 @@
+package themekit
+
+import (
+	"crypto/md5"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/fsnotify.v1"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	digest  [md5.Size]byte
+}
+
+// NewPollingWatcher produces the same events and errors as NewFileWatcher, by walking the
+// directory every interval instead of relying on filesystem notifications, which are not
+// delivered on many network filesystems, shared folders and container mounts. Closing stop
+// closes both returned channels.
+func NewPollingWatcher(dir string, interval time.Duration, filter EventFilter, stop chan bool) (events chan AssetEvent, errs chan error, err error) {
+	files, err := pollDirectory(dir, filter, map[string]fileState{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events = make(chan AssetEvent)
+	errs = make(chan error)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		defer close(errs)
+		defer close(events)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			current, err := pollDirectory(dir, filter, files)
+			if err != nil {
+				errs <- err
+				continue
+			}
+			for _, event := range compareSnapshots(files, current) {
+				if fsevent := HandleEvent(event); fsevent.IsValid() {
+					events <- fsevent
+				}
+			}
+			files = current
+		}
+	}()
+	return events, errs, nil
+}
+
+// pollDirectory records the state of every file in the directory that is not filtered out.
+// Files are only read again when their modification time or size differs from the previous state.
+func pollDirectory(dir string, filter EventFilter, previous map[string]fileState) (map[string]fileState, error) {
+	files := map[string]fileState{}
+	walkFunc := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if path != dir && filter.MatchesFilter(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		state := fileState{modTime: info.ModTime(), size: info.Size()}
+		if before, found := previous[path]; found && before.modTime.Equal(state.modTime) && before.size == state.size {
+			state.digest = before.digest
+		} else if data, err := ioutil.ReadFile(path); err == nil {
+			state.digest = md5.Sum(data)
+		}
+		files[path] = state
+		return nil
+	}
+	return files, filepath.Walk(dir, walkFunc)
+}
+
+// compareSnapshots returns the filesystem events that turn one snapshot into the other. Files
+// touched without their contents changing are left out.
+func compareSnapshots(before, after map[string]fileState) []fsnotify.Event {
+	var events []fsnotify.Event
+	for path, state := range after {
+		if previous, found := before[path]; !found {
+			events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Create})
+		} else if previous.digest != state.digest {
+			events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Write})
+		}
+	}
+	for path := range before {
+		if _, found := after[path]; !found {
+			events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Remove})
+		}
+	}
+	sort.Sort(byEventName(events))
+	return events
+}
+
+type byEventName []fsnotify.Event
+
+func (events byEventName) Len() int {
+	return len(events)
+}
+
+func (events byEventName) Swap(i, j int) {
+	events[i], events[j] = events[j], events[i]
+}
+
+func (events byEventName) Less(i, j int) bool {
+	return events[i].Name < events[j].Name
+}
@@ -0,0 +1,70 @@
+package themekit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollingForChanges(t *testing.T) {
+	root, _ := ioutil.TempDir("", "polled")
+	defer os.RemoveAll(root)
+	os.MkdirAll(filepath.Join(root, "snippets"), 0755)
+	os.MkdirAll(filepath.Join(root, "assets"), 0755)
+	ioutil.WriteFile(filepath.Join(root, "snippets", "card.liquid"), []byte("card"), 0644)
+	ioutil.WriteFile(filepath.Join(root, "snippets", "gone.liquid"), []byte("gone"), 0644)
+	ioutil.WriteFile(filepath.Join(root, "snippets", "touched.liquid"), []byte("same"), 0644)
+	filter := NewEventFilter([]string{"*.scss"})
+
+	before, err := pollDirectory(root, filter, map[string]fileState{})
+	assert.Nil(t, err)
+
+	ioutil.WriteFile(filepath.Join(root, "snippets", "card.liquid"), []byte("new card"), 0644)
+	os.Remove(filepath.Join(root, "snippets", "gone.liquid"))
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(root, "snippets", "touched.liquid"), later, later)
+	ioutil.WriteFile(filepath.Join(root, "assets", "app.js"), []byte("app"), 0644)
+	ioutil.WriteFile(filepath.Join(root, "assets", "ignored.scss"), []byte("ignored"), 0644)
+
+	after, err := pollDirectory(root, filter, before)
+	assert.Nil(t, err)
+
+	events := []string{}
+	for _, event := range compareSnapshots(before, after) {
+		events = append(events, HandleEvent(event).String())
+	}
+	assert.Equal(t, []string{
+		"assets/app.js|Update",
+		"snippets/card.liquid|Update",
+		"snippets/gone.liquid|Remove",
+	}, events)
+}
+
+func TestPollingWatcherProducesAssetEvents(t *testing.T) {
+	root, _ := ioutil.TempDir("", "polled")
+	defer os.RemoveAll(root)
+	os.MkdirAll(filepath.Join(root, "layout"), 0755)
+
+	stop := make(chan bool)
+	events, errs, err := NewPollingWatcher(root, 10*time.Millisecond, NewEventFilter([]string{}), stop)
+	assert.Nil(t, err)
+	ioutil.WriteFile(filepath.Join(root, "layout", "theme.liquid"), []byte("theme"), 0644)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "layout/theme.liquid", event.Asset().Key)
+		assert.Equal(t, Update, event.Type())
+	case <-time.After(time.Second):
+		assert.Fail(t, "No event was produced")
+	}
+
+	close(stop)
+	_, more := <-events
+	assert.False(t, more, "Stopping closes the events")
+	_, more = <-errs
+	assert.False(t, more, "Stopping closes the errors")
+}
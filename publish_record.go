@@ -0,0 +1,43 @@
+package themekit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// PublishRecord remembers which theme was live before an environment's theme was
+// published, so that the publish can be rolled back
+type PublishRecord struct {
+	ThemeID         int64  `json:"theme_id"`
+	PreviousThemeID int64  `json:"previous_theme_id"`
+	PublishedAt     string `json:"published_at"`
+}
+
+// PublishRecordPath returns the location of the publish record for an environment
+func PublishRecordPath(dir, environment string) string {
+	return filepath.Join(dir, ManifestDirectory, environment, "publish.json")
+}
+
+// LoadPublishRecord reads the last publish of an environment, found is false if
+// the environment was never published
+func LoadPublishRecord(dir, environment string) (record PublishRecord, found bool, err error) {
+	contents, err := ioutil.ReadFile(PublishRecordPath(dir, environment))
+	if os.IsNotExist(err) {
+		return record, false, nil
+	} else if err != nil {
+		return record, false, err
+	}
+	err = json.Unmarshal(contents, &record)
+	return record, err == nil, err
+}
+
+// Save writes the publish record for an environment
+func (r PublishRecord) Save(dir, environment string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(PublishRecordPath(dir, environment), data)
+}
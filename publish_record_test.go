@@ -0,0 +1,29 @@
+package themekit
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavingAndLoadingAPublishRecord(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "publish")
+	defer os.RemoveAll(dir)
+
+	_, found, err := LoadPublishRecord(dir, "staging")
+	assert.Nil(t, err)
+	assert.False(t, found, "Nothing was published yet")
+
+	record := PublishRecord{ThemeID: 2, PreviousThemeID: 1, PublishedAt: "2016-05-01T10:00:00Z"}
+	assert.Nil(t, record.Save(dir, "staging"))
+
+	loaded, found, err := LoadPublishRecord(dir, "staging")
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, record, loaded)
+
+	_, found, _ = LoadPublishRecord(dir, "production")
+	assert.False(t, found, "Records are kept per environment")
+}
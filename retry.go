@@ -0,0 +1,116 @@
+package themekit
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	// callLimitLeakRate is how long Shopify takes to free up one call in its API bucket
+	callLimitLeakRate = 500 * time.Millisecond
+	callLimitHeader   = "X-Shopify-Shop-Api-Call-Limit"
+)
+
+// retryPolicy decides whether a request that was throttled or failed on the server
+// should be attempted again, and how long to wait before doing so.
+type retryPolicy struct {
+	maxAttempts int
+	maxDelay    time.Duration
+	sleep       func(time.Duration)
+}
+
+func newRetryPolicy(config Configuration) retryPolicy {
+	return retryPolicy{
+		maxAttempts: config.MaxAttempts,
+		maxDelay:    time.Duration(config.MaxRetryDelay) * time.Second,
+		sleep:       time.Sleep,
+	}
+}
+
+// shouldRetry only retries throttled requests for methods that are not idempotent, like
+// creating a theme, since a failure may come after the server acted on the request.
+func (p retryPolicy) shouldRetry(method string, resp *http.Response, err error, attempt int) bool {
+	if attempt >= p.maxAttempts {
+		return false
+	}
+	throttled := err == nil && resp.StatusCode == 429
+	if !isIdempotent(method) {
+		return throttled
+	}
+	return throttled || err != nil || resp.StatusCode >= 500
+}
+
+func isIdempotent(method string) bool {
+	return method == "GET" || method == "PUT" || method == "DELETE"
+}
+
+// delay honours the server's Retry-After header, otherwise backs off exponentially with
+// jitter, waiting at least until the shop's API call limit has room for another call.
+func (p retryPolicy) delay(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return p.capped(wait)
+		}
+	}
+
+	backoff := retryBaseDelay << uint(attempt-1)
+	wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	if resp != nil {
+		if used, limit, ok := parseCallLimit(resp.Header.Get(callLimitHeader)); ok && used >= limit {
+			if full := time.Duration(used-limit+1) * callLimitLeakRate; full > wait {
+				wait = full
+			}
+		}
+	}
+	return p.capped(wait)
+}
+
+func (p retryPolicy) capped(wait time.Duration) time.Duration {
+	if wait > p.maxDelay {
+		return p.maxDelay
+	}
+	return wait
+}
+
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if len(header) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(header, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return date.Sub(time.Now()), true
+	}
+	return 0, false
+}
+
+// parseCallLimit reads headers like "32/40", the calls used and the size of the shop's API bucket
+func parseCallLimit(header string) (used, limit int, ok bool) {
+	parts := strings.Split(header, "/")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	used, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	limit, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || limit <= 0 {
+		return 0, 0, false
+	}
+	return used, limit, true
+}
+
+func discardBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,100 @@
+package themekit
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerformRetriesThrottledRequests(t *testing.T) {
+	bodies := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(429)
+		}
+	}))
+	defer ts.Close()
+	config := conf(ts)
+	config.MaxAttempts = 5
+	client := NewThemeClient(config)
+
+	event := client.Perform(TestEvent{asset: asset(), eventType: Update})
+
+	assert.True(t, event.Successful())
+	assert.Equal(t, 3, len(bodies))
+	assert.Equal(t, bodies[0], bodies[2], "the request body should be sent again")
+}
+
+func TestPerformGivesUpAfterMaxAttempts(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(503)
+		w.Write([]byte(`{"errors": {"asset": ["unavailable"]}}`))
+	}))
+	defer ts.Close()
+	config := conf(ts)
+	config.MaxAttempts = 2
+	client := NewThemeClient(config)
+
+	event := client.Perform(TestEvent{asset: asset(), eventType: Update})
+
+	assert.False(t, event.Successful())
+	assert.Equal(t, 503, event.(APIAssetEvent).Code)
+	assert.Equal(t, 2, requests)
+}
+
+func TestRetryPolicyDoesNotRetryClientErrors(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 5}
+	assert.False(t, policy.shouldRetry("PUT", &http.Response{StatusCode: 422}, nil, 1))
+	assert.True(t, policy.shouldRetry("PUT", &http.Response{StatusCode: 429}, nil, 1))
+	assert.True(t, policy.shouldRetry("PUT", &http.Response{StatusCode: 502}, nil, 4))
+	assert.False(t, policy.shouldRetry("PUT", &http.Response{StatusCode: 502}, nil, 5))
+}
+
+func TestRetryPolicyOnlyRetriesThrottledPosts(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 5}
+	assert.True(t, policy.shouldRetry("POST", &http.Response{StatusCode: 429}, nil, 1))
+	assert.False(t, policy.shouldRetry("POST", &http.Response{StatusCode: 502}, nil, 1))
+	assert.False(t, policy.shouldRetry("POST", nil, errors.New("connection reset"), 1))
+	assert.True(t, policy.shouldRetry("GET", nil, errors.New("connection reset"), 1))
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 5, maxDelay: 10 * time.Second}
+	response := func(headers map[string]string) *http.Response {
+		resp := &http.Response{StatusCode: 429, Header: http.Header{}}
+		for key, value := range headers {
+			resp.Header.Set(key, value)
+		}
+		return resp
+	}
+
+	assert.Equal(t, 2*time.Second, policy.delay(response(map[string]string{"Retry-After": "2"}), 1))
+	assert.Equal(t, 10*time.Second, policy.delay(response(map[string]string{"Retry-After": "60"}), 1), "delays are capped")
+	assert.Equal(t, 3*time.Second, policy.delay(response(map[string]string{callLimitHeader: "45/40"}), 1))
+
+	for attempt := 1; attempt <= 4; attempt++ {
+		backoff := retryBaseDelay << uint(attempt-1)
+		delay := policy.delay(nil, attempt)
+		assert.True(t, delay >= backoff/2 && delay <= backoff, "delay %s for attempt %d", delay, attempt)
+	}
+}
+
+func TestParsingTheCallLimitHeader(t *testing.T) {
+	used, limit, ok := parseCallLimit("32/40")
+	assert.True(t, ok)
+	assert.Equal(t, 32, used)
+	assert.Equal(t, 40, limit)
+
+	_, _, ok = parseCallLimit("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,161 @@
+package theme
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PackageDirectory writes a zip of the theme in dir that Shopify accepts. Only the files within
+// the AssetLocations that are not ignored are included, and the packaged keys are returned.
+func PackageDirectory(dir string, w io.Writer, ignore func(path string) bool) ([]string, error) {
+	files, err := findAllFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := zip.NewWriter(w)
+	keys := []string{}
+	for _, file := range files {
+		key, err := filepath.Rel(dir, file)
+		if err != nil {
+			return keys, err
+		}
+		key = toSlash(key)
+		if !IsAssetKey(key) || ignore(key) {
+			continue
+		}
+		if err := addToArchive(archive, key, file); err != nil {
+			return keys, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, archive.Close()
+}
+
+// UnpackArchive extracts the assets of a theme zip into dir and returns them
+func UnpackArchive(path, dir string, ignore func(path string) bool) ([]Asset, error) {
+	assets, err := LoadAssetsFromZip(path, ignore)
+	if err != nil {
+		return nil, err
+	}
+	for _, asset := range assets {
+		if !isWithinDirectory(asset.Key) {
+			return nil, fmt.Errorf("%s would be extracted outside of %s", asset.Key, dir)
+		}
+	}
+	for _, asset := range assets {
+		if err := extractAsset(dir, asset); err != nil {
+			return nil, err
+		}
+	}
+	return assets, nil
+}
+
+// LoadAssetsFromZip loads the assets in a theme archive. Archives that wrap the theme
+// in a single top level directory, as GitHub does, are loaded from within that directory.
+func LoadAssetsFromZip(path string, ignore func(path string) bool) ([]Asset, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	files := []*zip.File{}
+	for _, file := range archive.File {
+		if !file.FileInfo().IsDir() && !strings.HasPrefix(file.Name, "__MACOSX/") {
+			files = append(files, file)
+		}
+	}
+	root := archiveRoot(files)
+
+	assets := []Asset{}
+	for _, file := range files {
+		key := strings.TrimPrefix(file.Name, root)
+		if ignore(key) {
+			continue
+		}
+		data, err := readArchivedFile(file)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, newAsset(key, data))
+	}
+	sort.Sort(ByAsset(assets))
+	return assets, nil
+}
+
+// archiveRoot returns the directory, including its trailing slash, that contains every
+// file in the archive, or an empty string if the files are not wrapped in one
+func archiveRoot(files []*zip.File) string {
+	root := ""
+	for i, file := range files {
+		slash := strings.Index(file.Name, "/")
+		if slash < 0 {
+			return ""
+		}
+		if i == 0 {
+			root = file.Name[:slash+1]
+		} else if !strings.HasPrefix(file.Name, root) {
+			return ""
+		}
+	}
+	if isThemeDirectory(root) {
+		return ""
+	}
+	return root
+}
+
+func isThemeDirectory(dir string) bool {
+	switch strings.TrimSuffix(dir, "/") {
+	case "assets", "config", "layout", "locales", "sections", "snippets", "templates":
+		return true
+	}
+	return false
+}
+
+func addToArchive(archive *zip.Writer, key, filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entry, err := archive.Create(key)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, file)
+	return err
+}
+
+func isWithinDirectory(key string) bool {
+	key = filepath.Clean(filepath.FromSlash(key))
+	return !filepath.IsAbs(key) && key != ".." && !strings.HasPrefix(key, ".."+string(filepath.Separator))
+}
+
+func extractAsset(dir string, asset Asset) error {
+	data, err := asset.Contents()
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(dir, filepath.FromSlash(asset.Key))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+func readArchivedFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
@@ -0,0 +1,91 @@
+package theme
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadingAssetsFromAZip(t *testing.T) {
+	path := writeZip(t, map[string]string{
+		"Timber-master/layout/theme.liquid":  "{{ content_for_layout }}",
+		"Timber-master/templates/404.liquid": "Not found",
+		"Timber-master/config.yml":           "development:",
+		"__MACOSX/._theme.liquid":            "",
+	})
+	defer os.Remove(path)
+
+	assets, err := LoadAssetsFromZip(path, func(key string) bool { return key == "config.yml" })
+	assert.Nil(t, err)
+	assert.Equal(t, []Asset{
+		{Key: "layout/theme.liquid", Value: "{{ content_for_layout }}"},
+		{Key: "templates/404.liquid", Value: "Not found"},
+	}, assets)
+}
+
+func TestLoadingAssetsFromAZipWithoutARootDirectory(t *testing.T) {
+	path := writeZip(t, map[string]string{
+		"templates/index.liquid": "Home",
+		"templates/404.liquid":   "Not found",
+	})
+	defer os.Remove(path)
+
+	assets, err := LoadAssetsFromZip(path, func(string) bool { return false })
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(assets))
+	assert.Equal(t, "templates/404.liquid", assets[0].Key)
+}
+
+func writeZip(t *testing.T, files map[string]string) string {
+	file, err := ioutil.TempFile("", "theme-zip")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for name, contents := range files {
+		entry, err := writer.Create(name)
+		assert.Nil(t, err)
+		entry.Write([]byte(contents))
+	}
+	assert.Nil(t, writer.Close())
+	return file.Name()
+}
+
+func TestPackagingAndUnpackingADirectory(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "theme-package")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "layout"), 0755)
+	os.MkdirAll(filepath.Join(dir, "templates", "customers"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "layout", "theme.liquid"), []byte("{{ content_for_layout }}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "templates", "customers", "login.liquid"), []byte("Login"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "templates", "ignored.liquid"), []byte("Ignored"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "config.yml"), []byte("development:"), 0644)
+
+	archive, _ := ioutil.TempFile("", "theme-package")
+	defer os.Remove(archive.Name())
+	keys, err := PackageDirectory(dir, archive, func(key string) bool { return key == "templates/ignored.liquid" })
+	archive.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"layout/theme.liquid", "templates/customers/login.liquid"}, keys)
+
+	target, _ := ioutil.TempDir("", "theme-unpack")
+	defer os.RemoveAll(target)
+	assets, err := UnpackArchive(archive.Name(), target, func(string) bool { return false })
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(assets))
+	contents, err := ioutil.ReadFile(filepath.Join(target, "templates", "customers", "login.liquid"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Login", string(contents))
+}
+
+func TestUnpackingOutsideOfTheDirectory(t *testing.T) {
+	path := writeZip(t, map[string]string{"../escape.liquid": "Escaped", "templates/404.liquid": "Not found"})
+	defer os.Remove(path)
+
+	_, err := UnpackArchive(path, os.TempDir(), func(string) bool { return false })
+	assert.NotNil(t, err)
+}
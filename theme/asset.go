@@ -1,7 +1,9 @@
 package theme
 
 import (
+	"crypto/md5"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -11,11 +13,16 @@ import (
 	"strings"
 )
 
-// Asset ... TODO
+// Asset is a file within a theme. When listed from Shopify only the metadata is filled in and Value and Attachment
+// are empty until the asset's contents are retrieved.
 type Asset struct {
-	Key        string `json:"key"`
-	Value      string `json:"value,omitempty"`
-	Attachment string `json:"attachment,omitempty"`
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	Attachment  string `json:"attachment,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	RemoteSize  int    `json:"size,omitempty"`
+	Checksum    string `json:"checksum,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
 }
 
 func (a Asset) String() string {
@@ -27,12 +34,38 @@ func (a Asset) IsValid() bool {
 	return len(a.Key) > 0 && (len(a.Value) > 0 || len(a.Attachment) > 0)
 }
 
-// Size ... TODO
+// Size returns the length of the asset contents. Assets whose contents have not been retrieved report the size listed by Shopify.
 func (a Asset) Size() int {
 	if len(a.Value) > 0 {
 		return len(a.Value)
+	} else if len(a.Attachment) > 0 {
+		return len(a.Attachment)
 	}
-	return len(a.Attachment)
+	return a.RemoteSize
+}
+
+// HasContents reports whether the Value or Attachment of the asset has been loaded
+func (a Asset) HasContents() bool {
+	return len(a.Value) > 0 || len(a.Attachment) > 0
+}
+
+// Contents returns the raw bytes of the asset, decoding the attachment if necessary
+func (a Asset) Contents() ([]byte, error) {
+	if len(a.Value) > 0 {
+		return []byte(a.Value), nil
+	}
+	return base64.StdEncoding.DecodeString(a.Attachment)
+}
+
+// Digest returns the hex encoded MD5 digest of the asset contents, which is
+// comparable with the Checksum reported by the Asset API.
+func (a Asset) Digest() string {
+	data, err := a.Contents()
+	if err != nil {
+		return ""
+	}
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // ByAsset implements sort.Interface
@@ -113,13 +146,17 @@ func LoadAsset(root, filename string) (asset Asset, err error) {
 		return asset, fmt.Errorf("LoadAsset: %s", err)
 	}
 
-	asset = Asset{Key: toSlash(filename)}
-	if contentTypeFor(buffer) == "text" {
-		asset.Value = string(buffer)
+	return newAsset(filename, buffer), nil
+}
+
+func newAsset(key string, data []byte) Asset {
+	asset := Asset{Key: toSlash(key)}
+	if contentTypeFor(data) == "text" {
+		asset.Value = string(data)
 	} else {
-		asset.Attachment = encode64(buffer)
+		asset.Attachment = encode64(data)
 	}
-	return
+	return asset
 }
 
 func toSlash(path string) string {
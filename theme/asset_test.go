@@ -163,9 +163,24 @@ func TestSortListOfAssets(t *testing.T) {
 	assert.Equal(t, expected, input)
 }
 
+func TestDigestMatchesForValuesAndAttachments(t *testing.T) {
+	value := Asset{Key: "assets/hello.txt", Value: "hello world"}
+	attachment := Asset{Key: "assets/hello.txt", Attachment: encode64([]byte("hello world"))}
+	assert.Equal(t, "5eb63bbbe01eeed093cb22bb8f5acdc3", value.Digest())
+	assert.Equal(t, value.Digest(), attachment.Digest())
+	assert.Equal(t, "", Asset{Attachment: "not base64!"}.Digest())
+}
+
 func BinaryTestData() []byte {
 	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
 	buff := bytes.NewBuffer([]byte{})
 	png.Encode(buff, img)
 	return buff.Bytes()
 }
+
+func TestIsAssetKey(t *testing.T) {
+	assert.True(t, IsAssetKey("templates/customers/login.liquid"))
+	assert.True(t, IsAssetKey("assets/app.js"))
+	assert.False(t, IsAssetKey("templates/"))
+	assert.False(t, IsAssetKey("config.yml"))
+}
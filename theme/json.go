@@ -0,0 +1,191 @@
+package theme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// jsonShapes are the top level values Shopify expects in particular JSON assets
+var jsonShapes = map[string]string{
+	"config/settings_data.json":   "object",
+	"config/settings_schema.json": "array",
+}
+
+// ValidateJSON checks that a JSON asset can be parsed, reporting the line and column of
+// syntax errors. Assets that are not JSON have no problems.
+func ValidateJSON(asset Asset) []Problem {
+	if path.Ext(asset.Key) != ".json" {
+		return nil
+	}
+	data, err := asset.Contents()
+	if err != nil {
+		return []Problem{{Key: asset.Key, Message: fmt.Sprintf("could not be read: %s", err)}}
+	}
+
+	data = decodableJSON(data)
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		problem := Problem{Key: asset.Key, Message: fmt.Sprintf("is not valid JSON: %s", err)}
+		if syntaxError, ok := err.(*json.SyntaxError); ok {
+			problem.Line, problem.Column = jsonPosition(data, syntaxError.Offset)
+		}
+		return []Problem{problem}
+	}
+
+	shape := jsonShapes[asset.Key]
+	if strings.HasPrefix(asset.Key, "locales/") {
+		shape = "object"
+	}
+	if len(shape) > 0 && jsonShape(value) != shape {
+		return []Problem{{Key: asset.Key, Message: fmt.Sprintf("must contain a JSON %s", shape)}}
+	}
+	return nil
+}
+
+// byteOrderMark is written at the start of files by some editors, the JSON decoder rejects it
+var byteOrderMark = []byte("\ufeff")
+
+// decodableJSON prepares the contents of a JSON asset for the decoder, dropping a leading byte
+// order mark and blanking a leading comment
+func decodableJSON(data []byte) []byte {
+	return blankLeadingComment(bytes.TrimPrefix(data, byteOrderMark))
+}
+
+// blankLeadingComment replaces a /* ... */ comment at the start of a JSON file, like the one
+// the theme editor writes at the top of settings_data.json, with spaces. Line breaks are kept
+// so that the positions of syntax errors stay the same.
+func blankLeadingComment(data []byte) []byte {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if !bytes.HasPrefix(trimmed, []byte("/*")) {
+		return data
+	}
+	start := len(data) - len(trimmed)
+	end := bytes.Index(trimmed, []byte("*/"))
+	if end < 0 {
+		return data
+	}
+	end += start + len("*/")
+	blanked := append([]byte{}, data...)
+	for i := start; i < end; i++ {
+		if blanked[i] != '\n' && blanked[i] != '\r' {
+			blanked[i] = ' '
+		}
+	}
+	return blanked
+}
+
+// ValidateLocales checks that every locale file defines the same translations as the default
+// locale, locales/*.default.json. Locales that are not valid JSON are left to ValidateJSON.
+func ValidateLocales(assets []Asset) []Problem {
+	var defaultLocale *Asset
+	locales := []Asset{}
+	for i, asset := range assets {
+		if !strings.HasPrefix(asset.Key, "locales/") || path.Ext(asset.Key) != ".json" {
+			continue
+		}
+		if strings.HasSuffix(asset.Key, ".default.json") {
+			defaultLocale = &assets[i]
+		} else {
+			locales = append(locales, asset)
+		}
+	}
+	if defaultLocale == nil {
+		return nil
+	}
+	expected, ok := LocaleKeys(*defaultLocale)
+	if !ok {
+		return nil
+	}
+
+	problems := []Problem{}
+	for _, locale := range locales {
+		problems = append(problems, CompareLocale(locale, expected, defaultLocale.Key)...)
+	}
+	return problems
+}
+
+// CompareLocale reports the translations a locale is missing from, or has in addition to,
+// the keys of the default locale
+func CompareLocale(locale Asset, expected map[string]bool, defaultKey string) []Problem {
+	keys, ok := LocaleKeys(locale)
+	if !ok {
+		return nil
+	}
+	problems := []Problem{}
+	for _, key := range sortedKeys(expected) {
+		if !keys[key] {
+			problems = append(problems, Problem{Key: locale.Key, Message: fmt.Sprintf("is missing '%s' defined in %s", key, defaultKey)})
+		}
+	}
+	for _, key := range sortedKeys(keys) {
+		if !expected[key] {
+			problems = append(problems, Problem{Key: locale.Key, Message: fmt.Sprintf("defines '%s' which is not in %s", key, defaultKey)})
+		}
+	}
+	return problems
+}
+
+// LocaleKeys returns the dotted paths of every translation in a locale file, ok is false
+// when the locale is not valid JSON
+func LocaleKeys(locale Asset) (keys map[string]bool, ok bool) {
+	data, err := locale.Contents()
+	if err != nil {
+		return nil, false
+	}
+	var translations map[string]interface{}
+	if err := json.Unmarshal(decodableJSON(data), &translations); err != nil {
+		return nil, false
+	}
+	keys = map[string]bool{}
+	flattenKeys("", translations, keys)
+	return keys, true
+}
+
+func flattenKeys(prefix string, translations map[string]interface{}, keys map[string]bool) {
+	for name, value := range translations {
+		key := name
+		if len(prefix) > 0 {
+			key = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenKeys(key, nested, keys)
+		} else {
+			keys[key] = true
+		}
+	}
+}
+
+func sortedKeys(keys map[string]bool) []string {
+	sorted := []string{}
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func jsonShape(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return "value"
+}
+
+// jsonPosition converts the offset reported by encoding/json, which is just past the
+// offending byte, to the line and column of that byte
+func jsonPosition(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndex(before, []byte("\n")) - 1
+	return line, column
+}
@@ -0,0 +1,55 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatingJSONSyntax(t *testing.T) {
+	problems := ValidateJSON(Asset{Key: "config/settings_data.json", Value: "{\n  \"current\": \"Default\",\n  \"presets\": {,}\n}"})
+	assert.Equal(t, 1, len(problems))
+	assert.Equal(t, 3, problems[0].Line)
+	assert.Equal(t, 15, problems[0].Column)
+
+	assert.Equal(t, 0, len(ValidateJSON(Asset{Key: "config/settings_schema.json", Value: "[]"})))
+	assert.Equal(t, 0, len(ValidateJSON(Asset{Key: "templates/index.liquid", Value: "{"})))
+}
+
+func TestIgnoringTheThemeEditorHeaderComment(t *testing.T) {
+	header := "/*\n * ------------------------------------------------------------\n * IMPORTANT: The contents of this file are auto-generated.\n */\n"
+	assert.Equal(t, 0, len(ValidateJSON(Asset{Key: "config/settings_data.json", Value: header + "{\"current\": \"Default\"}"})))
+
+	problems := ValidateJSON(Asset{Key: "config/settings_data.json", Value: header + "{,}"})
+	assert.Equal(t, 1, len(problems))
+	assert.Equal(t, 5, problems[0].Line)
+	assert.Equal(t, 2, problems[0].Column)
+}
+
+func TestIgnoringAByteOrderMark(t *testing.T) {
+	assert.Equal(t, 0, len(ValidateJSON(Asset{Key: "config/settings_data.json", Value: "\ufeff{\"current\": \"Default\"}"})))
+	assert.Equal(t, 0, len(ValidateJSON(Asset{Key: "config/settings_data.json", Value: "\ufeff/* header */\n{\"current\": \"Default\"}"})))
+	keys, ok := LocaleKeys(Asset{Key: "locales/en.default.json", Value: "\ufeff{\"home\": \"Home\"}"})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]bool{"home": true}, keys)
+}
+
+func TestValidatingTheShapeOfJSONAssets(t *testing.T) {
+	problems := ValidateJSON(Asset{Key: "config/settings_schema.json", Value: "{}"})
+	assert.Equal(t, []Problem{{Key: "config/settings_schema.json", Message: "must contain a JSON array"}}, problems)
+
+	problems = ValidateJSON(Asset{Key: "locales/en.default.json", Value: "[]"})
+	assert.Equal(t, "must contain a JSON object", problems[0].Message)
+}
+
+func TestValidatingLocaleKeys(t *testing.T) {
+	problems := ValidateLocales([]Asset{
+		{Key: "locales/en.default.json", Value: `{"cart": {"title": "Cart", "empty": "Empty"}, "home": "Home"}`},
+		{Key: "locales/fr.json", Value: `{"cart": {"title": "Panier"}, "home": "Accueil", "extra": "En trop"}`},
+		{Key: "locales/de.json", Value: `{"cart": {"title": "Warenkorb", "empty": "Leer"}, "home": "Start"}`},
+	})
+	assert.Equal(t, []Problem{
+		{Key: "locales/fr.json", Message: "is missing 'cart.empty' defined in locales/en.default.json"},
+		{Key: "locales/fr.json", Message: "defines 'extra' which is not in locales/en.default.json"},
+	}, problems)
+}
@@ -0,0 +1,17 @@
+package theme
+
+import "strings"
+
+// AssetLocations are the directories of a theme that Shopify accepts assets in. Nested
+// locations come before the location that contains them.
+var AssetLocations = []string{"templates/customers/", "assets/", "config/", "layout/", "snippets/", "templates/", "locales/", "sections/"}
+
+// IsAssetKey reports whether the key is within one of the AssetLocations
+func IsAssetKey(key string) bool {
+	for _, dir := range AssetLocations {
+		if strings.HasPrefix(key, dir) && len(key) > len(dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package theme
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxAssetSize is the largest file, in bytes, Shopify accepts in a theme
+	MaxAssetSize = 20 * 1024 * 1024
+	// MaxTemplateSize is the largest Liquid file, in bytes, Shopify accepts in a theme
+	MaxTemplateSize = 256 * 1024
+)
+
+// RequiredKeys are the files every theme must contain
+var RequiredKeys = []string{
+	"layout/theme.liquid",
+	"templates/404.liquid",
+	"templates/article.liquid",
+	"templates/blog.liquid",
+	"templates/cart.liquid",
+	"templates/collection.liquid",
+	"templates/index.liquid",
+	"templates/page.liquid",
+	"templates/product.liquid",
+	"templates/search.liquid",
+}
+
+// locationExtensions lists the extensions allowed in the locations that restrict them
+var locationExtensions = map[string][]string{
+	"layout/":              {".liquid"},
+	"templates/":           {".liquid"},
+	"templates/customers/": {".liquid"},
+	"snippets/":            {".liquid"},
+	"sections/":            {".liquid"},
+	"locales/":             {".json"},
+	"config/":              {".json", ".html"},
+}
+
+// validFilename allows any name with an extension, except for whitespace and the characters
+// that are not allowed in filenames on every platform
+var validFilename = regexp.MustCompile(`^[^\s\\/:*?"<>|]+\.[^\s\\/:*?"<>|.]+$`)
+
+// Problem is something about an asset that Shopify would reject. Problems found in the contents
+// of an asset include the line and column they were found at.
+type Problem struct {
+	Key     string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p Problem) Error() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", p.Key, p.Line, p.Column, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Key, p.Message)
+}
+
+// Validate checks a complete theme, reporting every problem with its assets along with
+// the required files it is missing. Locales are not compared with the default locale, since
+// Shopify accepts them anyway, use ValidateLocales for that.
+func Validate(assets []Asset) []Problem {
+	problems := []Problem{}
+	keys := map[string]bool{}
+	for _, asset := range assets {
+		keys[asset.Key] = true
+		problems = append(problems, ValidateAsset(asset)...)
+	}
+	for _, key := range RequiredKeys {
+		if !keys[key] {
+			problems = append(problems, Problem{Key: key, Message: "is required but missing"})
+		}
+	}
+	sort.Stable(byProblemKey(problems))
+	return problems
+}
+
+// ValidateAsset checks that Shopify would accept the asset into a theme
+func ValidateAsset(asset Asset) []Problem {
+	problems := []Problem{}
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, Problem{Key: asset.Key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	location := assetLocation(asset.Key)
+	if len(location) == 0 {
+		if strings.Contains(asset.Key, "/") && IsAssetKey(asset.Key) {
+			report("is in a nested directory, only templates/customers may contain directories")
+		} else {
+			report("is not in one of %s", strings.Join(AssetLocations, ", "))
+		}
+		return problems
+	}
+
+	filename := path.Base(asset.Key)
+	if !validFilename.MatchString(filename) {
+		report(`has an invalid name, it needs an extension and cannot contain whitespace or any of \ : * ? " < > |`)
+	} else if extensions, found := locationExtensions[location]; found && !hasExtension(filename, extensions) {
+		report("must have one of the extensions %s in %s", strings.Join(extensions, ", "), location)
+	}
+
+	if asset.HasContents() {
+		data, err := asset.Contents()
+		if err != nil {
+			report("could not be read: %s", err)
+		} else if strings.HasSuffix(filename, ".liquid") && len(data) > MaxTemplateSize {
+			report("is %d bytes, Liquid files cannot be larger than %d bytes", len(data), MaxTemplateSize)
+		} else if len(data) > MaxAssetSize {
+			report("is %d bytes, files cannot be larger than %d bytes", len(data), MaxAssetSize)
+		}
+		problems = append(problems, ValidateJSON(asset)...)
+	}
+	return problems
+}
+
+// assetLocation returns the location the key is directly within, or an empty string when it
+// is not directly within any of the AssetLocations
+func assetLocation(key string) string {
+	for _, dir := range AssetLocations {
+		if strings.HasPrefix(key, dir) && !strings.Contains(key[len(dir):], "/") && len(key) > len(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+func hasExtension(filename string, extensions []string) bool {
+	for _, extension := range extensions {
+		if path.Ext(filename) == extension {
+			return true
+		}
+	}
+	return false
+}
+
+type byProblemKey []Problem
+
+func (p byProblemKey) Len() int {
+	return len(p)
+}
+
+func (p byProblemKey) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+func (p byProblemKey) Less(i, j int) bool {
+	return p[i].Key < p[j].Key
+}
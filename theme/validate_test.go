@@ -0,0 +1,46 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatingACompleteTheme(t *testing.T) {
+	assets := []Asset{
+		{Key: "assets/app.js", Value: "app()"},
+		{Key: "config/settings_data.json", Value: "{}"},
+		{Key: "templates/customers/login.liquid", Value: "Login"},
+	}
+	for _, key := range RequiredKeys {
+		assets = append(assets, Asset{Key: key, Value: "content"})
+	}
+	assert.Equal(t, []Problem{}, Validate(assets))
+}
+
+func TestValidatingReportsEveryProblem(t *testing.T) {
+	problems := Validate([]Asset{
+		{Key: "README.md", Value: "Read me"},
+		{Key: "assets/images/logo.png", Attachment: "aGVsbG8="},
+		{Key: "snippets/my snippet.liquid", Value: "Snippet"},
+		{Key: "assets/logo@2x.png", Attachment: "aGVsbG8="},
+		{Key: "assets/_partial.scss", Value: "a {}"},
+		{Key: "locales/en.yml", Value: "en:"},
+		{Key: "layout/theme.liquid", Value: strings.Repeat("a", MaxTemplateSize+1)},
+	})
+
+	messages := map[string]string{}
+	for _, problem := range problems {
+		messages[problem.Key] = problem.Message
+	}
+	assert.Contains(t, messages["README.md"], "is not in one of")
+	assert.Contains(t, messages["assets/images/logo.png"], "nested directory")
+	assert.Contains(t, messages["snippets/my snippet.liquid"], "invalid name")
+	assert.Contains(t, messages["locales/en.yml"], ".json")
+	assert.Equal(t, "", messages["assets/logo@2x.png"])
+	assert.Equal(t, "", messages["assets/_partial.scss"])
+	assert.Contains(t, messages["layout/theme.liquid"], "cannot be larger")
+	assert.Equal(t, "is required but missing", messages["templates/index.liquid"])
+	assert.Equal(t, 5+len(RequiredKeys)-1, len(problems))
+}
@@ -5,7 +5,9 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -20,11 +22,20 @@ import (
 
 const createThemeMaxRetries int = 3
 
+const (
+	// assetMetadataFields are retrieved when listing assets, leaving out their contents
+	assetMetadataFields = "key,content_type,size,updated_at,checksum"
+	// assetFields are retrieved when fetching a single asset
+	assetFields = "key,attachment,value,content_type,size,updated_at,checksum"
+)
+
 // ThemeClient ... TODO
 type ThemeClient struct {
-	config     Configuration
-	httpClient *http.Client
-	filter     EventFilter
+	config      Configuration
+	httpClient  *http.Client
+	filter      EventFilter
+	retry       retryPolicy
+	leakyBucket *bucket.LeakyBucket
 }
 
 type apiResponse struct {
@@ -58,6 +69,18 @@ func (e NonFatalNetworkError) Error() string {
 	return fmt.Sprintf("%d %s %s", e.Code, e.Verb, e.Message)
 }
 
+// IsNetworkFailure tells whether an error means Shopify could not be reached, or responded with a
+// server error or by throttling the request
+func IsNetworkFailure(err error) bool {
+	switch e := err.(type) {
+	case NonFatalNetworkError:
+		return e.Code == 429 || e.Code >= 500
+	case net.Error:
+		return true
+	}
+	return false
+}
+
 const (
 	// Update ... TODO
 	Update EventType = iota
@@ -74,23 +97,52 @@ type AssetEvent interface {
 // NewThemeClient ... TODO
 func NewThemeClient(config Configuration) ThemeClient {
 	return ThemeClient{
-		config:     config,
-		httpClient: newHTTPClient(config),
-		filter:     NewEventFilterFromPatternsAndFiles(config.IgnoredFiles, config.Ignores),
+		config:      config,
+		httpClient:  newHTTPClient(config),
+		filter:      NewEventFilterFromPatternsAndFiles(config.IgnoredFiles, config.Ignores),
+		retry:       newRetryPolicy(config),
+		leakyBucket: bucketFor(config),
 	}
 }
 
+type bucketKey struct {
+	shop             string
+	size, refillRate int
+}
+
+var (
+	buckets      = map[bucketKey]*bucket.LeakyBucket{}
+	bucketsMutex = &sync.Mutex{}
+)
+
+// bucketFor returns the leaky bucket of a shop, creating it the first time. Every client for the
+// shop shares it, since Shopify limits the API calls of a shop as a whole.
+func bucketFor(config Configuration) *bucket.LeakyBucket {
+	key := bucketKey{shop: config.Domain, size: config.BucketSize, refillRate: config.RefillRate}
+	if len(key.shop) == 0 {
+		key.shop = config.URL
+	}
+	bucketsMutex.Lock()
+	defer bucketsMutex.Unlock()
+	if _, found := buckets[key]; !found {
+		buckets[key] = bucket.NewLeakyBucket(config.BucketSize, config.RefillRate, 1)
+	}
+	return buckets[key]
+}
+
 // GetConfiguration ... TODO
 func (t ThemeClient) GetConfiguration() Configuration {
 	return t.config
 }
 
-// LeakyBucket ... TODO
+// LeakyBucket returns the bucket shared by every copy of the client. It is kept in
+// sync with the API call limit reported in Shopify's responses.
 func (t ThemeClient) LeakyBucket() *bucket.LeakyBucket {
-	return bucket.NewLeakyBucket(t.config.BucketSize, t.config.RefillRate, 1)
+	return t.leakyBucket
 }
 
-// AssetList ... TODO
+// AssetList lists the assets of the theme, sending any error met on errs. Only the metadata of
+// each asset is listed, use LoadContents to retrieve an asset's contents.
 func (t ThemeClient) AssetList() (results chan theme.Asset, errs chan error) {
 	results = make(chan theme.Asset)
 	errs = make(chan error)
@@ -101,7 +153,7 @@ func (t ThemeClient) AssetList() (results chan theme.Asset, errs chan error) {
 			return path
 		}
 
-		resp := t.query(queryBuilder)
+		resp := t.query(assetMetadataFields, queryBuilder)
 		if resp.err != nil {
 			errs <- resp.err
 		}
@@ -111,7 +163,7 @@ func (t ThemeClient) AssetList() (results chan theme.Asset, errs chan error) {
 			return
 		}
 		if resp.code >= 500 {
-			errs <- fmt.Errorf("Server responded with HTTP %d; try again in a few minutes.", resp.code)
+			errs <- NonFatalNetworkError{Code: resp.code, Verb: "GET", Message: "Server error; try again in a few minutes."}
 			return
 		}
 
@@ -132,9 +184,14 @@ func (t ThemeClient) AssetList() (results chan theme.Asset, errs chan error) {
 	return
 }
 
-// AssetListSync ... TODO
+// AssetListSync returns the listing of AssetList once it is complete. Errors are discarded, use
+// AssetList to handle them.
 func (t ThemeClient) AssetListSync() []theme.Asset {
-	ch, _ := t.AssetList()
+	ch, errs := t.AssetList()
+	go func() {
+		for range errs {
+		}
+	}()
 	results := []theme.Asset{}
 	for {
 		asset, more := <-ch
@@ -157,6 +214,21 @@ func (t ThemeClient) LocalAssets(dir string) []theme.Asset {
 	return assets
 }
 
+// ArchivedAssets loads the assets in a theme zip, skipping the files ignored by the configuration
+func (t ThemeClient) ArchivedAssets(path string) ([]theme.Asset, error) {
+	return theme.LoadAssetsFromZip(path, t.filter.MatchesFilter)
+}
+
+// PackageAssets writes a zip of the theme in dir, skipping the files ignored by the configuration
+func (t ThemeClient) PackageAssets(dir string, w io.Writer) ([]string, error) {
+	return theme.PackageDirectory(dir, w, t.filter.MatchesFilter)
+}
+
+// UnpackAssets extracts a theme zip into dir, skipping the files ignored by the configuration
+func (t ThemeClient) UnpackAssets(path, dir string) ([]theme.Asset, error) {
+	return theme.UnpackArchive(path, dir, t.filter.MatchesFilter)
+}
+
 // AssetRetrieval ... TODO
 type AssetRetrieval func(filename string) (theme.Asset, error)
 
@@ -166,7 +238,7 @@ func (t ThemeClient) Asset(filename string) (theme.Asset, error) {
 		return fmt.Sprintf("%s&asset[key]=%s", path, filename)
 	}
 
-	resp := t.query(queryBuilder)
+	resp := t.query(assetFields, queryBuilder)
 	if resp.err != nil {
 		return theme.Asset{}, resp.err
 	}
@@ -182,6 +254,15 @@ func (t ThemeClient) Asset(filename string) (theme.Asset, error) {
 	return asset["asset"], nil
 }
 
+// LoadContents returns the asset with its contents, retrieving them from Shopify
+// if they were not included in the listing.
+func (t ThemeClient) LoadContents(asset theme.Asset) (theme.Asset, error) {
+	if asset.HasContents() {
+		return asset, nil
+	}
+	return t.Asset(asset.Key)
+}
+
 // CreateTheme ... TODO
 func (t ThemeClient) CreateTheme(name, zipLocation string) (ThemeClient, chan ThemeEvent) {
 	var wg sync.WaitGroup
@@ -209,7 +290,7 @@ func (t ThemeClient) CreateTheme(name, zipLocation string) (ThemeClient, chan Th
 			go logEvent(themeEvent)
 		}
 		if retries >= createThemeMaxRetries {
-			err := fmt.Errorf(fmt.Sprintf("'%s' cannot be retrieved from Github.", zipLocation))
+			err := fmt.Errorf("'%s' cannot be retrieved from Github.", zipLocation)
 			NotifyError(err)
 		}
 		return
@@ -271,17 +352,11 @@ func (t ThemeClient) Perform(asset AssetEvent) ThemeEvent {
 	return processResponse(resp, err, asset)
 }
 
-func (t ThemeClient) query(queryBuilder func(path string) string) apiResponse {
-	path := fmt.Sprintf("%s?fields=key,attachment,value", t.config.AssetPath())
+func (t ThemeClient) query(fields string, queryBuilder func(path string) string) apiResponse {
+	path := fmt.Sprintf("%s?fields=%s", t.config.AssetPath(), fields)
 	path = queryBuilder(path)
 
-	req, err := http.NewRequest("GET", path, nil)
-	if err != nil {
-		return apiResponse{err: err}
-	}
-
-	t.config.AddHeaders(req)
-	resp, err := t.httpClient.Do(req)
+	resp, err := t.do("GET", path, nil)
 	if err != nil {
 		return apiResponse{err: err}
 	}
@@ -291,13 +366,8 @@ func (t ThemeClient) query(queryBuilder func(path string) string) apiResponse {
 }
 
 func (t ThemeClient) sendData(method, path string, body []byte) (result APIThemeEvent) {
-	req, err := http.NewRequest(method, path, bytes.NewBuffer(body))
-	if err != nil {
-		NotifyError(err)
-	}
-	t.config.AddHeaders(req)
-	resp, err := t.httpClient.Do(req)
-	if result = NewAPIThemeEvent(resp, err); result.Successful() {
+	resp, err := t.do(method, path, body)
+	if result = NewAPIThemeEvent(resp, err); err == nil {
 		defer resp.Body.Close()
 	}
 	return result
@@ -305,21 +375,45 @@ func (t ThemeClient) sendData(method, path string, body []byte) (result APITheme
 
 func (t ThemeClient) request(event AssetEvent, method string) (*http.Response, error) {
 	path := t.config.AssetPath()
-	data := map[string]theme.Asset{"asset": event.Asset()}
+	asset := event.Asset()
+	data := map[string]theme.Asset{"asset": theme.Asset{Key: asset.Key, Value: asset.Value, Attachment: asset.Attachment}}
 
 	encoded, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(method, path, bytes.NewBuffer(encoded))
+	return t.do(method, path, encoded)
+}
 
-	if err != nil {
-		return nil, err
+// do sends a request, attempting it again according to the retry policy when
+// it is throttled or fails on the server
+func (t ThemeClient) do(method, path string, body []byte) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest(method, path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		t.config.AddHeaders(req)
+		resp, err := t.httpClient.Do(req)
+		t.observeCallLimit(resp)
+		if !t.retry.shouldRetry(method, resp, err, attempt) {
+			return resp, err
+		}
+		delay := t.retry.delay(resp, attempt)
+		discardBody(resp)
+		t.retry.sleep(delay)
 	}
+}
 
-	t.config.AddHeaders(req)
-	return t.httpClient.Do(req)
+func (t ThemeClient) observeCallLimit(resp *http.Response) {
+	if resp == nil || t.leakyBucket == nil {
+		return
+	}
+	if used, limit, ok := parseCallLimit(resp.Header.Get(callLimitHeader)); ok {
+		t.leakyBucket.Resync(used, limit)
+	}
 }
 
 func processResponse(r *http.Response, err error, event AssetEvent) ThemeEvent {
@@ -332,6 +426,72 @@ func (t ThemeClient) isDoneProcessing(themeID int64) bool {
 	return themeEvent.Previewable
 }
 
+// Themes lists the themes on the shop along with their roles
+func (t ThemeClient) Themes() ([]theme.Theme, error) {
+	resp, err := t.do("GET", t.themesPath(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, NonFatalNetworkError{Code: resp.StatusCode, Verb: "GET", Message: string(body)}
+	}
+
+	var themes map[string][]theme.Theme
+	if err := json.Unmarshal(body, &themes); err != nil {
+		return nil, err
+	}
+	return themes["themes"], nil
+}
+
+// CreateEmptyTheme creates an unpublished theme without any source
+func (t ThemeClient) CreateEmptyTheme(name string) APIThemeEvent {
+	return t.sendTheme("POST", t.themesPath(), map[string]interface{}{"name": name, "role": "unpublished"})
+}
+
+// PublishTheme makes the theme the shop's main theme
+func (t ThemeClient) PublishTheme(themeID int64) APIThemeEvent {
+	return t.sendTheme("PUT", t.themePath(themeID), map[string]interface{}{"id": themeID, "role": "main"})
+}
+
+// RenameTheme changes the name of the theme
+func (t ThemeClient) RenameTheme(themeID int64, name string) APIThemeEvent {
+	return t.sendTheme("PUT", t.themePath(themeID), map[string]interface{}{"id": themeID, "name": name})
+}
+
+// DeleteTheme deletes the theme from the shop
+func (t ThemeClient) DeleteTheme(themeID int64) APIThemeEvent {
+	return t.sendData("DELETE", t.themePath(themeID), nil)
+}
+
+// ForTheme returns a client for another theme on the same shop. The client shares
+// the connection and leaky bucket of this one.
+func (t ThemeClient) ForTheme(themeID int64) ThemeClient {
+	t.config.ThemeID = themeID
+	t.config.URL = fmt.Sprintf("%s/themes/%d", t.config.AdminURL(), themeID)
+	return t
+}
+
+func (t ThemeClient) sendTheme(method, path string, attributes map[string]interface{}) APIThemeEvent {
+	data, err := json.Marshal(map[string]interface{}{"theme": attributes})
+	if err != nil {
+		return NewAPIThemeEvent(nil, err)
+	}
+	return t.sendData(method, path, data)
+}
+
+func (t ThemeClient) themesPath() string {
+	return fmt.Sprintf("%s/themes.json", t.config.AdminURL())
+}
+
+func (t ThemeClient) themePath(themeID int64) string {
+	return fmt.Sprintf("%s/themes/%d.json", t.config.AdminURL(), themeID)
+}
+
 // ExtractErrorMessage ... TODO
 func ExtractErrorMessage(data []byte, err error) string {
 	return extractAssetAPIErrors(data, err).Error()
@@ -340,11 +500,11 @@ func ExtractErrorMessage(data []byte, err error) string {
 func newHTTPClient(config Configuration) (client *http.Client) {
 	client = &http.Client{}
 	if len(config.Proxy) > 0 {
-		fmt.Println("Proxy URL detected from Configuration:", config.Proxy)
-		fmt.Println("SSL Certificate Validation will be disabled!")
+		Notice("Proxy URL detected from Configuration:", config.Proxy)
+		Notice("SSL Certificate Validation will be disabled!")
 		proxyURL, err := url.Parse(config.Proxy)
 		if err != nil {
-			fmt.Println("Proxy configuration invalid:", err)
+			Notice("Proxy configuration invalid:", err)
 		}
 		client.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL), TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 	}
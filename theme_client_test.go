@@ -43,6 +43,18 @@ func TestPerformWithRemoveAssetEvent(t *testing.T) {
 	client.Perform(asset)
 }
 
+func TestPerformRecordsTheChecksumOfTheUploadedAsset(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"asset": {"key": "assets/hello.txt", "checksum": "remote", "updated_at": "2016-06-01T10:00:00-04:00"}}`)
+	}))
+	defer ts.Close()
+	client := NewThemeClient(conf(ts))
+
+	event := client.Perform(TestEvent{asset: asset(), eventType: Update}).(APIAssetEvent)
+	assert.Equal(t, "remote", event.Checksum)
+	assert.Equal(t, "2016-06-01T10:00:00-04:00", event.UpdatedAt)
+}
+
 func TestPerformWithAssetEventThatDoesNotPassTheFilter(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Log("The request should never have been sent")
@@ -86,8 +98,8 @@ func TestProcessingAnEventsChannel(t *testing.T) {
 
 func TestRetrievingAnAssetList(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "fields=key,attachment,value", r.URL.RawQuery)
-		fmt.Fprintf(w, TestFixture("response_multi"))
+		assert.Equal(t, "fields=key,content_type,size,updated_at,checksum", r.URL.RawQuery)
+		fmt.Fprint(w, TestFixture("response_multi"))
 	}))
 
 	client := NewThemeClient(conf(ts))
@@ -95,6 +107,100 @@ func TestRetrievingAnAssetList(t *testing.T) {
 	assert.Equal(t, 2, count(assets))
 }
 
+func TestLoadingTheContentsOfAListedAsset(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "assets/hello.txt", r.URL.Query().Get("asset[key]"))
+		fmt.Fprint(w, TestFixture("response_single"))
+	}))
+	defer ts.Close()
+	client := NewThemeClient(conf(ts))
+
+	loaded, err := client.LoadContents(theme.Asset{Key: "assets/hello.txt", RemoteSize: 11})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", loaded.Value)
+
+	loaded, err = client.LoadContents(asset())
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello World", loaded.Value)
+	assert.Equal(t, 1, requests, "assets that already have contents should not be retrieved again")
+}
+
+func TestResponsesResyncTheLeakyBucket(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Shopify-Shop-Api-Call-Limit", "38/40")
+		fmt.Fprint(w, `{"asset":{"key":"templates/404.liquid","value":"404"}}`)
+	}))
+	defer ts.Close()
+
+	config := conf(ts)
+	config.BucketSize = 20
+	client := NewThemeClient(config)
+	client.LeakyBucket().TopUp()
+	_, err := client.Asset("templates/404.liquid")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, client.LeakyBucket().Available())
+	assert.Equal(t, 1, client.ForTheme(2).LeakyBucket().Available(), "Copies of the client share the bucket")
+	assert.Equal(t, 1, NewThemeClient(config).LeakyBucket().Available(), "Clients for the same shop share the bucket")
+}
+
+func TestListingThemes(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin/themes.json", r.URL.Path)
+		fmt.Fprint(w, `{"themes":[{"id":1,"name":"Timber","role":"main"},{"id":2,"name":"Debut","role":"unpublished"}]}`)
+	}))
+	defer ts.Close()
+
+	themes, err := adminClient(ts).Themes()
+	assert.Nil(t, err)
+	assert.Equal(t, []theme.Theme{
+		{ID: 1, Name: "Timber", Role: "main"},
+		{ID: 2, Name: "Debut", Role: "unpublished"},
+	}, themes)
+}
+
+func TestPublishingATheme(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/admin/themes/2.json", r.URL.Path)
+		var request map[string]map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&request)
+		assert.Equal(t, "main", request["theme"]["role"])
+		_, renamed := request["theme"]["name"]
+		assert.False(t, renamed, "Publishing should not change the name of the theme")
+		fmt.Fprint(w, `{"theme":{"id":2,"name":"Debut","role":"main"}}`)
+	}))
+	defer ts.Close()
+
+	event := adminClient(ts).PublishTheme(2)
+	assert.True(t, event.Successful())
+	assert.Equal(t, int64(2), event.ThemeID)
+	assert.Equal(t, "Debut", event.ThemeName)
+}
+
+func TestDeletingATheme(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/admin/themes/2.json", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors":"Not Found"}`)
+	}))
+	defer ts.Close()
+
+	event := adminClient(ts).DeleteTheme(2)
+	assert.False(t, event.Successful())
+	assert.NotNil(t, event.Error())
+}
+
+func TestAClientForAnotherTheme(t *testing.T) {
+	client := NewThemeClient(Configuration{Domain: "shop.myshopify.com", ThemeID: 1})
+	other := client.ForTheme(2)
+	assert.Equal(t, int64(2), other.GetConfiguration().ThemeID)
+	assert.Equal(t, "https://shop.myshopify.com/admin/themes/2/assets.json", other.GetConfiguration().AssetPath())
+	assert.Equal(t, client.LeakyBucket(), other.LeakyBucket())
+}
+
 func TestRetrievingLocalAssets(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	client := NewThemeClient(conf(ts))
@@ -116,7 +222,7 @@ func TestRetrievingLocalAssetsWithSubdirectories(t *testing.T) {
 
 func TestRetrievingAnAssetListThatIncludesCompiledAssets(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintf(w, TestFixture("assets_response_from_shopify"))
+		fmt.Fprint(w, TestFixture("assets_response_from_shopify"))
 	}))
 
 	var expected map[string][]theme.Asset
@@ -136,8 +242,8 @@ func TestRetrievingAnAssetListThatIncludesCompiledAssets(t *testing.T) {
 
 func TestRetrievingASingleAsset(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "fields=key,attachment,value&asset[key]=assets/foo.txt", r.URL.RawQuery)
-		fmt.Fprintf(w, TestFixture("response_single"))
+		assert.Equal(t, "fields=key,attachment,value,content_type,size,updated_at,checksum&asset[key]=assets/foo.txt", r.URL.RawQuery)
+		fmt.Fprint(w, TestFixture("response_single"))
 	}))
 
 	client := NewThemeClient(conf(ts))
@@ -182,6 +288,18 @@ func TestThemeClientAssetListOnUnauthorized(t *testing.T) {
 	assert.Equal(t, "Server responded with HTTP 401; please check your credentials.", err.Error())
 }
 
+func TestTellingNetworkFailures(t *testing.T) {
+	_, err := http.Get("http://127.0.0.1:0")
+	assert.True(t, IsNetworkFailure(err), "Shopify could not be reached")
+	assert.True(t, IsNetworkFailure(NonFatalNetworkError{Code: 503, Verb: "GET"}))
+	assert.True(t, IsNetworkFailure(NonFatalNetworkError{Code: 429, Verb: "PUT"}))
+	assert.False(t, IsNetworkFailure(NonFatalNetworkError{Code: 404, Verb: "GET"}))
+	assert.False(t, IsNetworkFailure(fmt.Errorf("configuration error")))
+
+	assert.Equal(t, NetworkFailureExitCode, exitStatusFor(NonFatalNetworkError{Code: 502, Verb: "GET"}))
+	assert.Equal(t, 1, exitStatusFor(fmt.Errorf("configuration error")))
+}
+
 func asset() theme.Asset {
 	return theme.Asset{Key: "assets/hello.txt", Value: "Hello World"}
 }
@@ -190,6 +308,12 @@ func conf(server *httptest.Server) Configuration {
 	return Configuration{URL: server.URL, AccessToken: "abra"}
 }
 
+func adminClient(server *httptest.Server) ThemeClient {
+	client := NewThemeClient(Configuration{Domain: server.Listener.Addr().String(), AccessToken: "abra"})
+	client.httpClient = server.Client()
+	return client
+}
+
 func drain(channel chan ThemeEvent) {
 	for {
 		_, more := <-channel
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Shopify/themekit/theme"
 )
@@ -52,8 +53,11 @@ type APIAssetEvent struct {
 	AssetKey  string `json:"asset_key"`
 	EventType string `json:"event_type"`
 	Code      int    `json:"status_code"`
-	err       error  `json:"error,omitempty"` // TODO: err is unexported; json binding is not going to work
-	etype     string `json:"type"`            // TODO: same here, unexported, no json binding
+	Checksum  string `json:"checksum,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	asset     theme.Asset
+	err       error
+	etype     string
 }
 
 // NewAPIAssetEvent ... TODO
@@ -61,6 +65,7 @@ func NewAPIAssetEvent(r *http.Response, e AssetEvent, err error) APIAssetEvent {
 	event := APIAssetEvent{
 		AssetKey:  e.Asset().Key,
 		EventType: e.Type().String(),
+		asset:     e.Asset(),
 		etype:     "APIAssetEvent",
 	}
 	if err != nil {
@@ -72,12 +77,27 @@ func NewAPIAssetEvent(r *http.Response, e AssetEvent, err error) APIAssetEvent {
 		event.Code = r.StatusCode
 		if !event.Successful() {
 			event.err = extractAssetAPIErrors(ioutil.ReadAll(r.Body))
+		} else {
+			populateAssetData(&event, r)
 		}
 	}
 
 	return event
 }
 
+func populateAssetData(e *APIAssetEvent, r *http.Response) {
+	var container map[string]theme.Asset
+	if data, err := ioutil.ReadAll(r.Body); err == nil {
+		json.Unmarshal(data, &container)
+	}
+	asset := container["asset"]
+	e.Checksum = asset.Checksum
+	e.UpdatedAt = asset.UpdatedAt
+	if len(e.Checksum) == 0 && e.EventType == Update.String() {
+		e.Checksum = e.asset.Digest()
+	}
+}
+
 func (a APIAssetEvent) String() string {
 	if a.Successful() {
 		return fmt.Sprintf(
@@ -123,7 +143,12 @@ func (a APIAssetEvent) Error() error {
 
 // AsJSON ... TODO
 func (a APIAssetEvent) AsJSON() ([]byte, error) {
-	return json.Marshal(a)
+	type event APIAssetEvent
+	return json.Marshal(struct {
+		event
+		Error string `json:"error,omitempty"`
+		Type  string `json:"type"`
+	}{event(a), errorMessage(a.err), a.etype})
 }
 
 // AssetError ... TODO
@@ -138,8 +163,8 @@ type APIThemeEvent struct {
 	ThemeID     int64  `json:"theme_id"`
 	Code        int    `json:"status_code"`
 	Previewable bool   `json:"previewable,omitempty"`
-	err         error  `json:"error,omitempty"` // err unexported
-	etype       string `json:"type"`            // etype unexported
+	err         error
+	etype       string
 }
 
 // NewAPIThemeEvent ... TODO
@@ -187,7 +212,12 @@ func (t APIThemeEvent) Error() error {
 
 // AsJSON ... TODO
 func (t APIThemeEvent) AsJSON() ([]byte, error) {
-	return json.Marshal(t)
+	type event APIThemeEvent
+	return json.Marshal(struct {
+		event
+		Error string `json:"error,omitempty"`
+		Type  string `json:"type"`
+	}{event(t), errorMessage(t.err), t.etype})
 }
 
 func (t *APIThemeEvent) markIfHasError(err error) bool {
@@ -221,6 +251,35 @@ func populateAPIErrorData(e *APIThemeEvent, r *http.Response) {
 	}
 }
 
+// EncodeEvent describes an event as a single line JSON object for machine readable output. The
+// fields of the event are included along with its message, whether it was successful, its error
+// and the time it was encoded.
+func EncodeEvent(event ThemeEvent, at time.Time) ([]byte, error) {
+	fields := map[string]interface{}{}
+	if data, err := event.AsJSON(); err == nil {
+		json.Unmarshal(data, &fields)
+	}
+	if _, found := fields["type"]; !found {
+		fields["type"] = fmt.Sprintf("%T", event)
+	}
+	if message := event.String(); len(message) > 0 {
+		fields["message"] = message
+	}
+	if err := event.Error(); err != nil {
+		fields["error"] = err.Error()
+	}
+	fields["successful"] = event.Successful()
+	fields["timestamp"] = at.Format(time.RFC3339Nano)
+	return json.Marshal(fields)
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
 func extractAssetAPIErrors(data []byte, err error) error {
 	if err != nil {
 		return err
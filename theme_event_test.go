@@ -0,0 +1,49 @@
+package themekit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodingAnAPIAssetEvent(t *testing.T) {
+	SetColorOutput(false)
+	defer SetColorOutput(true)
+
+	event := APIAssetEvent{
+		Host:      "shop.myshopify.com",
+		AssetKey:  "templates/index.liquid",
+		EventType: "Update",
+		Code:      422,
+		err:       errors.New("Liquid syntax error"),
+		etype:     "APIAssetEvent",
+	}
+	at := time.Date(2016, 5, 1, 10, 0, 0, 0, time.UTC)
+	data, err := EncodeEvent(event, at)
+	assert.Nil(t, err)
+
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "APIAssetEvent", fields["type"])
+	assert.Equal(t, "templates/index.liquid", fields["asset_key"])
+	assert.Equal(t, float64(422), fields["status_code"])
+	assert.Equal(t, "Liquid syntax error", fields["error"])
+	assert.Equal(t, false, fields["successful"])
+	assert.Equal(t, "2016-05-01T10:00:00Z", fields["timestamp"])
+	assert.Contains(t, fields["message"].(string), "Could not upload templates/index.liquid")
+}
+
+func TestEncodingAnAPIThemeEventIncludesItsType(t *testing.T) {
+	data, err := APIThemeEvent{ThemeName: "Debut", ThemeID: 2, Code: 200, etype: "APIThemeEvent"}.AsJSON()
+	assert.Nil(t, err)
+
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "APIThemeEvent", fields["type"])
+	assert.Equal(t, "Debut", fields["name"])
+	_, found := fields["error"]
+	assert.False(t, found, "Successful events have no error")
+}
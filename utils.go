@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"image"
 	"image/png"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -27,6 +28,23 @@ var BlueText = color.New(color.FgBlue).SprintFunc()
 // GreenText ... TODO
 var GreenText = color.New(color.FgGreen).SprintFunc()
 
+// SetColorOutput enables or disables the colours added by RedText, YellowText, BlueText and GreenText
+func SetColorOutput(enabled bool) {
+	color.NoColor = !enabled
+}
+
+var noticeOutput io.Writer = os.Stdout
+
+// SetNoticeOutput sets where Notice writes, so that notices can be kept out of machine readable output
+func SetNoticeOutput(w io.Writer) {
+	noticeOutput = w
+}
+
+// Notice writes a message meant for people, like warnings and prompts, rather than command output
+func Notice(a ...interface{}) {
+	fmt.Fprintln(noticeOutput, a...)
+}
+
 // TestFixture ... TODO
 func TestFixture(name string) string {
 	return string(RawTestFixture(name))
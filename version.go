@@ -99,7 +99,7 @@ func ApplyUpdate(updateURL, digest string) error {
 	})
 	if err != nil {
 		if rerr := update.RollbackError(err); rerr != nil {
-			fmt.Printf("Failed to rollback from bad update: %v", rerr)
+			Notice(fmt.Sprintf("Failed to rollback from bad update: %v", rerr))
 		}
 	}
 	return err